@@ -0,0 +1,52 @@
+package gormx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// WithAuditUser sets fn to return the user a transaction acts on behalf of,
+// from its context. The created_by and updated_by columns of the models
+// created in a transaction, and the updated_by column of the ones updated,
+// are then set to that user. If fn reports no user, they are left untouched.
+func WithAuditUser(fn func(ctx context.Context) (string, bool)) Option {
+	return func(g *gormx) error {
+		g.auditUser = fn
+		return nil
+	}
+}
+
+// registerAuditCallbacks installs the callbacks setting the audit columns of
+// WithAuditUser on db.
+func registerAuditCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("gormx:audit_create", setAuditColumns("created_by", "updated_by")); err != nil {
+		return err
+	}
+	return cb.Update().Before("gorm:update").Register("gormx:audit_update", setAuditColumns("updated_by"))
+}
+
+func setAuditColumns(columns ...string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Schema == nil {
+			return
+		}
+
+		g, ok := fromStatement(db)
+		if !ok || g.auditUser == nil {
+			return
+		}
+
+		user, ok := g.auditUser(db.Statement.Context)
+		if !ok {
+			return
+		}
+
+		for _, column := range columns {
+			if db.Statement.Schema.LookUpField(column) != nil {
+				db.Statement.SetColumn(column, user)
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type auditUserKey struct{}
+
+func TestWithAuditUser(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithAuditUser(func(ctx context.Context) (string, bool) {
+		user, ok := ctx.Value(auditUserKey{}).(string)
+		return user, ok
+	}))
+	defer gx.Close()
+
+	alice := context.WithValue(context.Background(), auditUserKey{}, "alice")
+	bob := context.WithValue(context.Background(), auditUserKey{}, "bob")
+
+	tx := gx.BeginTxx(alice)
+	assert.NoError(tx.Create(&models.T6{ID: "abc", Name: "first"}).Error)
+	assert.NoError(tx.Commitx())
+
+	tx = gx.BeginTxx(bob)
+	assert.NoError(tx.Model(&models.T6{ID: "abc"}).Update("name", "second").Error)
+	assert.NoError(tx.Commitx())
+
+	var t6 models.T6
+	assert.NoError(gx.Gorm().First(&t6, "id = ?", "abc").Error)
+	assert.Equal(models.T6{ID: "abc", Name: "second", CreatedBy: "alice", UpdatedBy: "bob"}, t6)
+
+	// without a user, the columns are left untouched
+	tx = gx.BeginTxx(context.Background())
+	assert.NoError(tx.Create(&models.T6{ID: "def", CreatedBy: "system"}).Error)
+	assert.NoError(tx.Commitx())
+
+	assert.NoError(gx.Gorm().First(&t6, "id = ?", "def").Error)
+	assert.Equal("system", t6.CreatedBy)
+	assert.Empty(t6.UpdatedBy)
+}
@@ -0,0 +1,111 @@
+package gormx
+
+import "gorm.io/gorm"
+
+// autoSavepointKey is the statement instance setting under which the
+// savepoint created before a write statement is stored.
+const autoSavepointKey = "gormx:auto_savepoint"
+
+// WithAutoSavepointPerStatement wraps every write statement run in a
+// transaction in its own savepoint, rolled back if the statement fails and
+// released otherwise. A failed statement is then undone on its own, leaving
+// the transaction usable, which Postgres otherwise refuses until it is rolled
+// back.
+//
+// It adds two round trips per write statement, so it is disabled by default.
+func WithAutoSavepointPerStatement() Option {
+	return func(g *gormx) error {
+		g.autoSavepoint = true
+		return nil
+	}
+}
+
+// registerAutoSavepointCallbacks installs the callbacks creating and
+// resolving the savepoints of WithAutoSavepointPerStatement around the write
+// processors of db.
+func registerAutoSavepointCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("*").Register("gormx:before_create_savepoint", beforeWrite); err != nil {
+		return err
+	}
+	if err := cb.Create().After("*").Register("gormx:after_create_savepoint", afterWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("*").Register("gormx:before_update_savepoint", beforeWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("*").Register("gormx:after_update_savepoint", afterWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("*").Register("gormx:before_delete_savepoint", beforeWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("*").Register("gormx:after_delete_savepoint", afterWrite); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("*").Register("gormx:before_raw_savepoint", beforeWrite); err != nil {
+		return err
+	}
+	return cb.Raw().After("*").Register("gormx:after_raw_savepoint", afterWrite)
+}
+
+// autoSavepointOwner returns the gormx running db in one of its
+// transactions with WithAutoSavepointPerStatement enabled, if any.
+func autoSavepointOwner(db *gorm.DB) (*gormx, bool) {
+	g, ok := fromStatement(db)
+	if !ok || !g.autoSavepoint || g.internal || !g.inTransaction() || db.DryRun {
+		return nil, false
+	}
+	return g, true
+}
+
+func beforeWrite(db *gorm.DB) {
+	g, ok := autoSavepointOwner(db)
+	if !ok || db.Error != nil {
+		return
+	}
+
+	// the savepoint is created on the connection directly, as running it
+	// through db would replace the statement being built
+	name := g.newSavePointID()
+	sql := "SAVEPOINT " + db.Statement.Quote(name)
+	if g.savePointSQL != nil {
+		sql = g.savePointSQL.begin(name)
+	}
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, sql); err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(autoSavepointKey, name)
+}
+
+func afterWrite(db *gorm.DB) {
+	v, ok := db.InstanceGet(autoSavepointKey)
+	if !ok {
+		return
+	}
+	name := v.(string)
+
+	sql := "RELEASE SAVEPOINT " + db.Statement.Quote(name)
+	if db.Error != nil {
+		sql = "ROLLBACK TO SAVEPOINT " + db.Statement.Quote(name)
+	}
+
+	if g, _ := fromStatement(db); g.savePointSQL != nil {
+		custom := g.savePointSQL
+		switch {
+		case db.Error != nil:
+			sql = custom.rollback(name)
+		case custom.release != nil:
+			sql = custom.release(name)
+		default:
+			// kept until the transaction resolves
+			return
+		}
+	}
+
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, sql); err != nil {
+		db.AddError(err)
+	}
+}
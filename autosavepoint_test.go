@@ -0,0 +1,64 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAutoSavepointPerStatement(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithAutoSavepointPerStatement())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(tx.Create(&models.T2{ID: "abc"}).Error)
+	assert.Error(tx.Create(&models.T2{ID: "abc"}).Error)
+	assert.NoError(tx.Exec("INSERT INTO t3(id) VALUES('abc')").Error)
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 1)
+
+	var t3s []T3
+	gx.Gorm().Find(&t3s)
+	assert.Len(t3s, 1)
+}
+
+func TestPostgresWithAutoSavepointPerStatement(t *testing.T) {
+	assert := assert.New(t)
+	db := createPostgresConnection(t)
+	gx, _ := gormx.New(db, gormx.WithAutoSavepointPerStatement())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.Error(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+	// the failed statement alone has been rolled back, the transaction
+	// isn't aborted
+	assert.NoError(tx.Exec("INSERT INTO t2(id) VALUES('abc')").Error)
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 1)
+}
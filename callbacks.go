@@ -0,0 +1,92 @@
+package gormx
+
+import "gorm.io/gorm"
+
+// txSettingKey is the statement setting under which a transaction handle
+// stores its owning gormx, so callbacks can attribute statements to it.
+const txSettingKey = "gormx:tx"
+
+// registerCallbacks installs the gormx callbacks on db. Callbacks are shared
+// by every gormx created from the same gorm DB, so they are only registered
+// once.
+func registerCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if cb.Raw().Get("gormx:after_raw") != nil {
+		return nil
+	}
+
+	if err := registerAutoSavepointCallbacks(db); err != nil {
+		return err
+	}
+	if err := registerAuditCallbacks(db); err != nil {
+		return err
+	}
+	if err := registerDDLGuardCallbacks(db); err != nil {
+		return err
+	}
+	if err := registerRequestIDCallbacks(db); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("gormx:after_create", afterStatement(true)); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("gormx:after_update", afterStatement(true)); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("gormx:after_delete", afterStatement(true)); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("gormx:after_query", afterStatement(false)); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("gormx:after_row", afterStatement(false)); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("gormx:after_raw", afterStatement(true))
+}
+
+// fromStatement returns the gormx owning the transaction db runs in, if any.
+func fromStatement(db *gorm.DB) (*gormx, bool) {
+	v, ok := db.Get(txSettingKey)
+	if !ok {
+		return nil, false
+	}
+	g, ok := v.(*gormx)
+	return g, ok
+}
+
+// IsGormxTransaction reports whether db runs in a transaction managed by
+// gormx, as opposed to an ad hoc statement on the gorm DB. It lets callbacks
+// registered on the gorm DB apply to gormx transactions only, e.g.:
+//
+//	db.Callback().Create().Before("gorm:create").Register("audit", func(db *gorm.DB) {
+//		if gormx.IsGormxTransaction(db) {
+//			...
+//		}
+//	})
+func IsGormxTransaction(db *gorm.DB) bool {
+	g, ok := fromStatement(db)
+	return ok && g.inTransaction()
+}
+
+func afterStatement(write bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		g, ok := fromStatement(db)
+		if !ok {
+			return
+		}
+
+		db.Error = g.expiredError(wrapConnectionLost(db.Error))
+		if g.internal || db.DryRun {
+			return
+		}
+
+		g.lastErr = db.Error
+		g.stats.Statements++
+		if write {
+			g.stats.RowsAffected += db.RowsAffected
+		} else {
+			g.stats.RowsScanned += db.RowsAffected
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIsGormxTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var inTx, adHoc []string
+	db.Callback().Create().Before("gorm:create").Register("gormx_test:marker", func(db *gorm.DB) {
+		id := db.Statement.Dest.(*T1).ID
+		if gormx.IsGormxTransaction(db) {
+			inTx = append(inTx, id)
+		} else {
+			adHoc = append(adHoc, id)
+		}
+	})
+
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Create(&T1{ID: "a"}).Error)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Tx().Create(&T1{ID: "b"}).Error)
+	assert.NoError(nested.Commitx())
+	assert.NoError(gx.Gorm().Create(&T1{ID: "c"}).Error)
+	assert.NoError(tx.Commitx())
+
+	assert.NoError(gx.Gorm().Create(&T1{ID: "d"}).Error)
+
+	assert.Equal([]string{"a", "b"}, inTx)
+	assert.Equal([]string{"c", "d"}, adHoc)
+}
@@ -0,0 +1,95 @@
+package gormx
+
+// Checkpoint makes the work done so far in the current transaction durable,
+// while keeping the transaction open at its current depth: the database
+// transaction is committed, and a new one begun right away, recreating the
+// savepoints of the open nested transactions. Rolling back, at any depth,
+// then only undoes the work done after the checkpoint.
+//
+// The new database transaction takes a new snapshot, so other transactions'
+// changes committed in between become visible, and the locks held so far are
+// released. On MySQL and Postgres, it is chained to the committed one with
+// COMMIT AND CHAIN, on the same connection, so session state such as user
+// variables persists and no other connection is needed. Elsewhere, it is
+// begun on the connection the transaction is pinned to, e.g. by
+// BeginTxxOnConn, or on any connection of the pool otherwise. The commit and
+// rollback hooks only run once the top-level transaction resolves.
+func (g *gormx) Checkpoint() error {
+	if !g.inTransaction() {
+		return ErrNotInTransaction
+	}
+
+	if err := g.checkGoroutine(); err != nil {
+		return err
+	}
+
+	if g.expired() {
+		return g.expire()
+	}
+
+	g.addTrace(TraceCheckpoint, g.savePointIDs[len(g.savePointIDs)-1])
+
+	g.resetSession()
+	if err := g.commitAndChain(); err != nil {
+		g.end()
+		g.mustSucceed(err)
+		g.runHooks(g.onRollback)
+		g.runDeferred(false)
+		return err
+	}
+	g.initSession()
+
+	g.internal = true
+	for _, id := range g.savePointIDs {
+		if id != "" && id != joinedSavePointID {
+			g.DB = g.savePoint(id)
+		}
+	}
+	g.internal = false
+
+	// the work done so far is committed whatever happens next, so the
+	// transaction is resolved as committed if it can't go on
+	if err := g.DB.Error; err != nil {
+		g.Rollback()
+		g.end()
+		g.mustSucceed(err)
+		g.runHooks(g.onCommit)
+		g.runDeferred(true)
+		return err
+	}
+	return nil
+}
+
+// commitAndChain commits the database transaction and begins a new one in
+// its place. On error, the transaction is left rolled back, or failed.
+func (g *gormx) commitAndChain() error {
+	switch g.dialect() {
+	case "mysql", "postgres":
+		g.internal = true
+		err := g.DB.Exec("COMMIT AND CHAIN").Error
+		g.internal = false
+		if err != nil {
+			// releases the connection, whatever state the transaction is in
+			g.Rollback()
+			return g.withBeginStack(wrapConnectionLost(err))
+		}
+		return nil
+	}
+
+	ctx := g.DB.Statement.Context
+	if err := g.withBeginStack(wrapConnectionLost(g.Commit().Error)); err != nil {
+		return err
+	}
+
+	db := g.db.WithContext(ctx)
+	if g.conn != nil {
+		db.Statement.ConnPool = g.conn
+	}
+	if g.txOptions != nil {
+		g.DB = db.Begin(g.txOptions)
+	} else {
+		g.DB = db.Begin()
+	}
+	g.DB.Statement.Settings.Store(txSettingKey, g)
+	return nil
+}
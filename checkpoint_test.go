@@ -0,0 +1,125 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGormx_Checkpoint(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorIs(gx.Checkpoint(), gormx.ErrNotInTransaction)
+
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('checkpointed')").Error)
+	assert.NoError(nested.Checkpoint())
+	assert.Len(gx.ActiveSavepoints(), 2)
+
+	// the checkpointed work is visible outside of the transaction
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	// rolling back the nested transaction undoes the later work only
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('nested')").Error)
+	assert.NoError(nested.Rollbackx())
+
+	assert.NoError(tx.Exec("INSERT INTO t2(id) VALUES('outer')").Error)
+	assert.NoError(tx.Rollbackx())
+	assert.Zero(gx.OpenTransactions())
+
+	gx.Gorm().Find(&t1s)
+	assert.Equal([]T1{{ID: "checkpointed"}}, t1s)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+}
+
+func TestGormx_Checkpoint_Connection(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	connectionID := func(tx *gormx.Transaction) (id int64) {
+		assert.NoError(tx.Raw("SELECT CONNECTION_ID()").Scan(&id).Error)
+		return id
+	}
+
+	// a pinned transaction keeps its connection and session state
+	tx, err := gx.BeginTxxOnConn(ctx)
+	assert.NoError(err)
+	id := connectionID(tx)
+	assert.NoError(tx.Exec("SET @gormx_var = 'abc'").Error)
+	assert.NoError(tx.Checkpoint())
+	assert.Equal(id, connectionID(tx))
+
+	var value string
+	tx.Raw("SELECT @gormx_var").Scan(&value)
+	assert.Equal("abc", value)
+	assert.NoError(tx.Commitx())
+
+	// so does any other transaction, chained on its connection
+	tx = gx.BeginTxx(ctx)
+	id = connectionID(tx)
+	assert.NoError(tx.Exec("SET @gormx_var = 'def'").Error)
+	assert.NoError(tx.Checkpoint())
+	assert.False(tx.IsPinned())
+	assert.Equal(id, connectionID(tx))
+
+	tx.Raw("SELECT @gormx_var").Scan(&value)
+	assert.Equal("def", value)
+	assert.NoError(tx.Rollbackx())
+}
+
+func TestGormx_Checkpoint_SingleConnection(t *testing.T) {
+	connections := map[string]func(testing.TB) *gorm.DB{
+		"mysql":    createConnection,
+		"postgres": createPostgresConnection,
+	}
+
+	for name, connect := range connections {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			db := connect(t)
+
+			// no other connection is available to the checkpoint
+			sqlDB, _ := db.DB()
+			sqlDB.SetMaxOpenConns(1)
+
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			tx := gx.BeginTxx(ctx)
+			nested := gx.BeginTxx(ctx)
+			assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('checkpointed')").Error)
+			assert.NoError(nested.Checkpoint())
+
+			assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('nested')").Error)
+			assert.NoError(nested.Rollbackx())
+			assert.NoError(tx.Commitx())
+			assert.Zero(gx.OpenTransactions())
+
+			var t1s []T1
+			gx.Gorm().Find(&t1s)
+			assert.Equal([]T1{{ID: "checkpointed"}}, t1s)
+		})
+	}
+}
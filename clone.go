@@ -0,0 +1,42 @@
+package gormx
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// WithDB returns a new Gormx sharing the configuration of g, such as its
+// hooks, options and savepoint settings, but running on db, e.g. to fail
+// over to another primary manually. The new Gormx has no transaction and its
+// own open transaction and duration stats. Closing it closes db, not the DB of
+// g.
+func (g *gormx) WithDB(db *gorm.DB) (Gormx, error) {
+	if db == nil {
+		return nil, ErrInvalidGormDB
+	}
+
+	f := g.fork()
+	f.db = db
+	f.openTransactions = new(atomic.Int64)
+	f.durations = &durationSampler{}
+	f.stopStatsCallback = nil
+
+	if f.checkSavePoints && !f.supportsSavePoints() {
+		return nil, fmt.Errorf("%w: savepoints unsupported by the %s dialector", ErrIncompatibleOption, db.Dialector.Name())
+	}
+
+	f.detach()
+
+	if err := registerCallbacks(db); err != nil {
+		return nil, err
+	}
+	f.checkNestedTransaction()
+
+	if err := f.startStatsCallback(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
@@ -0,0 +1,61 @@
+package gormx_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_WithDB(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var committed int
+	gx, _ := gormx.New(db,
+		gormx.WithSavepointPrefix("failover_"),
+		gormx.OnCommit(func(stats gormx.TxStats) {
+			committed++
+		}),
+	)
+	defer gx.Close()
+
+	_, err := gx.WithDB(nil)
+	assert.ErrorIs(err, gormx.ErrInvalidGormDB)
+
+	clone, err := gx.WithDB(createPostgresConnection(t))
+	if !assert.NoError(err) {
+		return
+	}
+	defer clone.Close()
+
+	ctx := context.Background()
+	tx := clone.BeginTxx(ctx)
+	nested := clone.BeginTxx(ctx)
+
+	// the configuration carries over
+	savepoints := clone.ActiveSavepoints()
+	if assert.Len(savepoints, 2) {
+		assert.True(strings.HasPrefix(savepoints[1].ID, "failover_"))
+	}
+
+	assert.NoError(nested.Create(&models.T1{ID: "failover"}).Error)
+	assert.NoError(nested.Commitx())
+
+	// the transaction state is its own
+	assert.Zero(gx.OpenTransactions())
+	assert.Equal(1, clone.OpenTransactions())
+
+	assert.NoError(tx.Commitx())
+	assert.Equal(1, committed)
+
+	// the queries went to the new DB only
+	var t1s []models.T1
+	clone.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+}
@@ -0,0 +1,26 @@
+package gormx
+
+import "context"
+
+// CopyRows copies the rows of fromTable matching the where condition, with
+// args as its placeholder values, into toTable and returns the number of rows
+// copied. An empty where copies all the rows. The tables must have the same
+// columns, in the same order.
+//
+// It runs in the open transaction, if any, so the copy can be made atomic
+// with, for example, the deletion of the copied rows.
+func (g *gormx) CopyRows(ctx context.Context, fromTable, toTable string, where string, args ...any) (int64, error) {
+	if err := validateIdentifiers(fromTable, toTable); err != nil {
+		return 0, err
+	}
+
+	db := handle(ctx, g)
+
+	sql := "INSERT INTO " + db.Statement.Quote(toTable) + " SELECT * FROM " + db.Statement.Quote(fromTable)
+	if where != "" {
+		sql += " WHERE " + where
+	}
+
+	res := db.Exec(sql, args...)
+	return res.RowsAffected, translateError(res.Error)
+}
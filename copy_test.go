@@ -0,0 +1,49 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_CopyRows(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	db.Exec("INSERT INTO t1(id) VALUES('a1'), ('a2'), ('b1')")
+
+	tx := gx.BeginTxx(ctx)
+	copied, err := tx.CopyRows(ctx, "t1", "t2", "id LIKE ?", "a%")
+	assert.NoError(err)
+	assert.Equal(int64(2), copied)
+	assert.NoError(tx.Exec("DELETE FROM t1 WHERE id LIKE ?", "a%").Error)
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Order("id").Find(&t2s)
+	assert.Equal([]T2{{ID: "a1"}, {ID: "a2"}}, t2s)
+
+	// copies are undone along with the transaction
+	tx = gx.BeginTxx(ctx)
+	copied, err = tx.CopyRows(ctx, "t1", "t3", "")
+	assert.NoError(err)
+	assert.Equal(int64(1), copied)
+	assert.NoError(tx.Rollbackx())
+
+	var t3s []T3
+	gx.Gorm().Find(&t3s)
+	assert.Len(t3s, 0)
+
+	_, err = gx.CopyRows(ctx, "t1; DROP TABLE t1", "t2", "")
+	assert.ErrorIs(err, gormx.ErrInvalidIdentifier)
+}
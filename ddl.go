@@ -0,0 +1,61 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrImplicitCommit is returned when running, within a transaction guarded
+// by WithDDLGuard, a statement that implicitly commits it.
+var ErrImplicitCommit = errors.New("statement causes an implicit commit")
+
+// implicitCommitRegexp matches the MySQL statements causing an implicit
+// commit, after any leading comment.
+var implicitCommitRegexp = regexp.MustCompile(`(?is)^\s*(?:/\*.*?\*/\s*)*(ALTER|CREATE|DROP|RENAME|TRUNCATE|GRANT|REVOKE|LOCK\s+TABLES?|ANALYZE|OPTIMIZE|REPAIR)\b(\s+TEMPORARY\b)?`)
+
+// WithDDLGuard makes statements that implicitly commit the transaction they
+// run in, such as CREATE TABLE or ALTER TABLE on MySQL, fail with
+// ErrImplicitCommit instead of silently ending the transaction and its
+// savepoints. Temporary tables, which don't commit, are allowed.
+//
+// It only applies to MySQL, where DDL isn't transactional.
+func WithDDLGuard() Option {
+	return func(g *gormx) error {
+		g.ddlGuard = true
+		return nil
+	}
+}
+
+// registerDDLGuardCallbacks installs the callback of WithDDLGuard on db.
+func registerDDLGuardCallbacks(db *gorm.DB) error {
+	return db.Callback().Raw().Before("gorm:raw").Register("gormx:ddl_guard", guardDDL)
+}
+
+func guardDDL(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+
+	g, ok := fromStatement(db)
+	if !ok || !g.ddlGuard || g.internal || g.dialect() != "mysql" {
+		return
+	}
+
+	if statement := implicitCommit(db.Statement.SQL.String()); statement != "" {
+		db.AddError(fmt.Errorf("%w: %s", ErrImplicitCommit, statement))
+	}
+}
+
+// implicitCommit returns the keyword of sql if it causes an implicit commit
+// on MySQL, and an empty string otherwise.
+func implicitCommit(sql string) string {
+	m := implicitCommitRegexp.FindStringSubmatch(sql)
+	if m == nil || m[2] != "" {
+		return ""
+	}
+	return strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))
+}
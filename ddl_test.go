@@ -0,0 +1,42 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDDLGuard(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithDDLGuard())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+	err := tx.Exec("ALTER TABLE t1 ADD COLUMN name varchar(255)").Error
+	assert.ErrorIs(err, gormx.ErrImplicitCommit)
+	assert.ErrorContains(err, "ALTER")
+
+	err = tx.Exec("/* migration */ lock tables t1 write").Error
+	assert.ErrorIs(err, gormx.ErrImplicitCommit)
+
+	// temporary tables don't commit
+	assert.NoError(tx.Exec("CREATE TEMPORARY TABLE tmp_t1 (id varchar(255))").Error)
+	assert.NoError(tx.Exec("DROP TEMPORARY TABLE tmp_t1").Error)
+
+	// the transaction is still open and can be rolled back
+	assert.NoError(tx.Rollbackx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	// outside of a transaction, DDL runs
+	assert.NoError(gx.Gorm().Exec("ALTER TABLE t1 COMMENT = 'guarded'").Error)
+}
@@ -0,0 +1,27 @@
+package gormx
+
+// Defer registers fn to be called once the current top-level transaction
+// resolves, with whether it committed, e.g. to release resources such as
+// temporary files or locks tied to the transaction. Like deferred calls,
+// the functions run in the reverse order of their registration.
+//
+// It returns ErrNotInTransaction outside of a transaction.
+func (g *gormx) Defer(fn func(committed bool)) error {
+	if !g.inTransaction() {
+		return ErrNotInTransaction
+	}
+
+	g.deferred = append(g.deferred, fn)
+	return nil
+}
+
+// runDeferred calls the functions registered with Defer for the resolved
+// top-level transaction.
+func (g *gormx) runDeferred(committed bool) {
+	deferred := g.deferred
+	g.deferred = nil
+
+	for i := len(deferred) - 1; i >= 0; i-- {
+		deferred[i](committed)
+	}
+}
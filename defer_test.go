@@ -0,0 +1,53 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_Defer(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorIs(gx.Defer(func(bool) {}), gormx.ErrNotInTransaction)
+
+	var calls []string
+	record := func(name string) func(bool) {
+		return func(committed bool) {
+			if committed {
+				name += ":committed"
+			} else {
+				name += ":rolled back"
+			}
+			calls = append(calls, name)
+		}
+	}
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Defer(record("a")))
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Defer(record("b")))
+	assert.NoError(nested.Commitx())
+	assert.Empty(calls)
+	assert.NoError(tx.Commitx())
+	assert.Equal([]string{"b:committed", "a:committed"}, calls)
+
+	calls = nil
+	tx = gx.BeginTxx(ctx)
+	assert.NoError(tx.Defer(record("c")))
+	assert.NoError(tx.Rollbackx())
+	assert.Equal([]string{"c:rolled back"}, calls)
+
+	// functions don't carry over to the next transaction
+	calls = nil
+	tx = gx.BeginTxx(ctx)
+	assert.NoError(tx.Commitx())
+	assert.Empty(calls)
+}
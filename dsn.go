@@ -0,0 +1,182 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ErrInvalidDSN is returned by Connect in strict DSN mode when the DSN
+// lacks recommended parameters.
+var ErrInvalidDSN = errors.New("invalid DSN")
+
+// WithStrictDSN makes Connect fail with ErrInvalidDSN when a MySQL DSN lacks
+// parseTime=true or charset=utf8mb4, instead of logging a warning.
+func WithStrictDSN() Option {
+	return func(g *gormx) error {
+		g.strictDSN = true
+		return nil
+	}
+}
+
+// WithLocation sets the location MySQL DATETIME and TIMESTAMP values are
+// read in by setting the loc parameter of the DSN, which requires
+// parseTime=true. It is only supported by Connect, which opens the
+// connection again with the updated DSN: New returns ErrIncompatibleOption.
+func WithLocation(loc *time.Location) Option {
+	return func(g *gormx) error {
+		if loc == nil {
+			return ErrInvalidDSN
+		}
+		g.location = loc
+		return nil
+	}
+}
+
+// applicationNameVariable is the MySQL user variable set by
+// WithApplicationName.
+const applicationNameVariable = "@application_name"
+
+var applicationNameRegexp = regexp.MustCompile(`^[\w.:/@-]+$`)
+
+// WithApplicationName labels the connections with name, for DBAs to
+// attribute queries to services: on MySQL, the @application_name user
+// variable of every connection is set to name, which makes it visible in
+// performance_schema.user_variables_by_thread. The variable is set through
+// the DSN, so like WithLocation, it is only supported by Connect.
+func WithApplicationName(name string) Option {
+	return func(g *gormx) error {
+		if !applicationNameRegexp.MatchString(name) {
+			return fmt.Errorf("%w: invalid application name %q", ErrInvalidDSN, name)
+		}
+		g.applicationName = name
+		return nil
+	}
+}
+
+// rewritesDSN reports whether options applied through the DSN are set.
+func (g *gormx) rewritesDSN() bool {
+	return g.location != nil || g.applicationName != ""
+}
+
+// rewriteDSN returns dsn with the parameters of the options applied through
+// the DSN, or dsn itself if it already has them.
+func (g *gormx) rewriteDSN(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+
+	changed := false
+	if g.location != nil {
+		if !cfg.ParseTime {
+			return "", fmt.Errorf("%w: loc requires parseTime=true", ErrInvalidDSN)
+		}
+		if cfg.Loc.String() != g.location.String() {
+			cfg.Loc = g.location
+			changed = true
+		}
+	}
+
+	if g.applicationName != "" {
+		// the driver sets unknown parameters as variables when connecting
+		value := "'" + g.applicationName + "'"
+		if cfg.Params[applicationNameVariable] != value {
+			if cfg.Params == nil {
+				cfg.Params = map[string]string{}
+			}
+			cfg.Params[applicationNameVariable] = value
+			changed = true
+		}
+	}
+
+	if !changed {
+		return dsn, nil
+	}
+	return cfg.FormatDSN(), nil
+}
+
+// DSNInfo holds the components of a MySQL DSN.
+type DSNInfo struct {
+	User     string
+	Net      string
+	Host     string
+	Port     string
+	Database string
+	// Params holds every parameter of the DSN, e.g. "parseTime" or "charset".
+	Params map[string]string
+}
+
+// ParseDSN validates a MySQL DSN and extracts its components.
+func ParseDSN(dsn string) (DSNInfo, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return DSNInfo{}, err
+	}
+
+	info := DSNInfo{
+		User:     cfg.User,
+		Net:      cfg.Net,
+		Host:     cfg.Addr,
+		Database: cfg.DBName,
+		Params:   map[string]string{},
+	}
+
+	if cfg.Net == "tcp" {
+		if host, port, err := net.SplitHostPort(cfg.Addr); err == nil {
+			info.Host, info.Port = host, port
+		}
+	}
+
+	// the driver consumes known parameters into dedicated fields, so
+	// they are read again from the raw DSN.
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		values, err := url.ParseQuery(dsn[i+1:])
+		if err != nil {
+			return DSNInfo{}, err
+		}
+		for k := range values {
+			info.Params[k] = values.Get(k)
+		}
+	}
+
+	return info, nil
+}
+
+// checkDSN warns, or fails in strict mode, when a MySQL DSN lacks
+// parseTime=true or charset=utf8mb4.
+func (g *gormx) checkDSN(dsn string) error {
+	if g.dialect() != "mysql" {
+		return nil
+	}
+
+	info, err := ParseDSN(dsn)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	if info.Params["parseTime"] != "true" {
+		missing = append(missing, "parseTime=true")
+	}
+	if info.Params["charset"] != "utf8mb4" {
+		missing = append(missing, "charset=utf8mb4")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	err = fmt.Errorf("%w: missing %s", ErrInvalidDSN, strings.Join(missing, ", "))
+	if g.strictDSN {
+		return err
+	}
+
+	g.db.Logger.Warn(g.defaultCtx, "gormx: %s", err)
+	return nil
+}
@@ -0,0 +1,162 @@
+package gormx_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestParseDSN(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name       string
+		arg        string
+		assertions func(gormx.DSNInfo, error)
+	}
+
+	testCases := []testCase{
+		{
+			name: "test datasource",
+			arg:  fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10)),
+			assertions: func(info gormx.DSNInfo, err error) {
+				assert.NoError(err)
+				assert.Equal("gormx", info.User)
+				assert.Equal("tcp", info.Net)
+				assert.Equal("localhost", info.Host)
+				assert.Equal("3366", info.Port)
+				assert.Equal("gormx", info.Database)
+				assert.Equal(map[string]string{"charset": "utf8mb4", "parseTime": "true"}, info.Params)
+			},
+		},
+		{
+			name: "unix socket",
+			arg:  "gormx@unix(/tmp/mysql.sock)/gormx",
+			assertions: func(info gormx.DSNInfo, err error) {
+				assert.NoError(err)
+				assert.Equal("unix", info.Net)
+				assert.Equal("/tmp/mysql.sock", info.Host)
+				assert.Empty(info.Port)
+				assert.Empty(info.Params)
+			},
+		},
+		{
+			name: "invalid datasource",
+			arg:  "gormx:gormx@tcp(localhost:3366",
+			assertions: func(info gormx.DSNInfo, err error) {
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := gormx.ParseDSN(tc.arg)
+			tc.assertions(info, err)
+		})
+	}
+}
+
+func TestConnect_DSNCheck(t *testing.T) {
+	assert := assert.New(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4", strconv.FormatInt(port, 10))
+
+	type testCase struct {
+		name       string
+		options    []gormx.Option
+		assertions func(gormx.Gormx, error, *recordingLogger)
+	}
+
+	testCases := []testCase{
+		{
+			name: "warning",
+			assertions: func(gx gormx.Gormx, err error, rec *recordingLogger) {
+				assert.NoError(err)
+				assert.NotNil(gx)
+				assert.Len(rec.Messages(), 1)
+				assert.Contains(rec.Messages()[0], "parseTime=true")
+			},
+		},
+		{
+			name:    "strict",
+			options: []gormx.Option{gormx.WithStrictDSN()},
+			assertions: func(gx gormx.Gormx, err error, rec *recordingLogger) {
+				assert.Nil(gx)
+				assert.ErrorIs(err, gormx.ErrInvalidDSN)
+				assert.ErrorContains(err, "parseTime=true")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := &recordingLogger{}
+			gx, err := gormx.Connect(dataSource, &gorm.Config{Logger: rec}, tc.options...)
+			tc.assertions(gx, err, rec)
+
+			if gx != nil {
+				gx.Close()
+			}
+		})
+	}
+}
+
+func TestConnect_WithLocation(t *testing.T) {
+	assert := assert.New(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+
+	gx, err := gormx.Connect(dataSource, new(gorm.Config), gormx.WithLocation(loc))
+	if !assert.NoError(err) {
+		return
+	}
+	defer gx.Close()
+
+	var ts time.Time
+	assert.NoError(gx.Gorm().Raw("SELECT CAST('2024-01-02 03:04:05' AS DATETIME)").Scan(&ts).Error)
+	assert.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, loc), ts)
+	assert.Equal("Asia/Tokyo", ts.Location().String())
+
+	// the location requires parseTime
+	withoutParseTime := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4", strconv.FormatInt(port, 10))
+	_, err = gormx.Connect(withoutParseTime, new(gorm.Config), gormx.WithLocation(loc))
+	assert.ErrorIs(err, gormx.ErrInvalidDSN)
+
+	// the DSN of an existing gorm DB can't be changed
+	_, err = gormx.New(createConnection(t), gormx.WithLocation(loc))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
+
+func TestConnect_WithApplicationName(t *testing.T) {
+	assert := assert.New(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
+
+	gx, err := gormx.Connect(dataSource, new(gorm.Config), gormx.WithApplicationName("billing"))
+	if !assert.NoError(err) {
+		return
+	}
+	defer gx.Close()
+
+	var name string
+	assert.NoError(gx.Gorm().Raw("SELECT @application_name").Scan(&name).Error)
+	assert.Equal("billing", name)
+
+	// every connection is labelled, transactions included
+	tx := gx.BeginTxx(context.Background())
+	assert.NoError(tx.Raw("SELECT @application_name").Scan(&name).Error)
+	assert.Equal("billing", name)
+	assert.NoError(tx.Commitx())
+
+	_, err = gormx.Connect(dataSource, new(gorm.Config), gormx.WithApplicationName("billing'; DROP TABLE t1"))
+	assert.ErrorIs(err, gormx.ErrInvalidDSN)
+}
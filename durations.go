@@ -0,0 +1,74 @@
+package gormx
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// durationSampleSize is the number of recent transaction durations kept to
+// compute DurationStats.
+const durationSampleSize = 1024
+
+// DurationSummary summarises the durations of recent top-level transactions.
+type DurationSummary struct {
+	// Count is the number of durations the summary is computed from, at most
+	// the last 1024.
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// durationSampler keeps the most recent transaction durations in a ring
+// buffer.
+type durationSampler struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (s *durationSampler) add(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) < durationSampleSize {
+		s.samples = append(s.samples, d)
+		return
+	}
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % durationSampleSize
+}
+
+func (s *durationSampler) summary() DurationSummary {
+	s.mu.Lock()
+	sorted := append([]time.Duration(nil), s.samples...)
+	s.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return DurationSummary{}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p int) time.Duration {
+		// nearest-rank method
+		rank := (p*len(sorted) + 99) / 100
+		return sorted[rank-1]
+	}
+
+	return DurationSummary{
+		Count: len(sorted),
+		P50:   percentile(50),
+		P95:   percentile(95),
+		P99:   percentile(99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// DurationStats returns percentiles of the durations of the last 1024
+// top-level transactions resolved on gx and its independent transactions,
+// for ad hoc diagnosis at runtime.
+func (g *gormx) DurationStats() DurationSummary {
+	return g.durations.summary()
+}
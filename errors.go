@@ -0,0 +1,174 @@
+package gormx
+
+import (
+	"database/sql/driver"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned by the helpers, such as Repository, when no
+	// record matches. It also matches gorm.ErrRecordNotFound.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicateKey is returned by the helpers when a write violates a
+	// primary key or unique constraint: MySQL's ER_DUP_ENTRY (1062) or
+	// Postgres' unique_violation (23505).
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrForeignKeyViolation is returned by the helpers when a write violates
+	// a foreign key constraint: MySQL's ER_ROW_IS_REFERENCED_2 (1451) and
+	// ER_NO_REFERENCED_ROW_2 (1452), or Postgres' foreign_key_violation
+	// (23503).
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+)
+
+// ErrConnectionLost is returned when the connection of a transaction is lost,
+// e.g. with MySQL's "server has gone away" errors. A transaction can't be
+// resumed on another connection, so the whole transaction must be retried.
+// The original error remains available through errors.As.
+var ErrConnectionLost = errors.New("connection lost")
+
+// sqlStater is implemented by errors carrying a SQLSTATE code, such as the
+// ones returned by the Postgres driver.
+type sqlStater interface {
+	SQLState() string
+}
+
+// sqlState returns the SQLSTATE code of err, if any.
+func sqlState(err error) string {
+	var e sqlStater
+	if errors.As(err, &e) {
+		return e.SQLState()
+	}
+	return ""
+}
+
+// isSerializationFailure reports whether err is Postgres'
+// serialization_failure (40001).
+func isSerializationFailure(err error) bool {
+	return sqlState(err) == "40001"
+}
+
+// isRetryableTransaction reports whether err is a deadlock or a serialization
+// failure, after which the transaction may succeed if run again: MySQL's
+// ER_LOCK_DEADLOCK (1213), or Postgres' serialization_failure (40001) and
+// deadlock_detected (40P01).
+func isRetryableTransaction(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213
+	}
+
+	switch sqlState(err) {
+	case "40001", "40P01":
+		return true
+	}
+	return false
+}
+
+// isConnectionLost reports whether err means the connection has been closed
+// by the server or the network: MySQL's CR_SERVER_GONE_ERROR (2006) and
+// CR_SERVER_LOST (2013), or the bad connection errors the driver returns
+// in their place.
+func isConnectionLost(err error) bool {
+	if err == nil || errors.Is(err, ErrConnectionLost) {
+		return false
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 2006 || mysqlErr.Number == 2013
+	}
+	return isBadConnection(err)
+}
+
+// isBadConnection reports whether err is caused by a stale connection.
+func isBadConnection(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn)
+}
+
+// connectionLostError wraps an error for which the connection was lost, to
+// match both ErrConnectionLost and the original error.
+type connectionLostError struct {
+	err error
+}
+
+func (e *connectionLostError) Error() string {
+	return ErrConnectionLost.Error() + ": " + e.err.Error()
+}
+
+func (e *connectionLostError) Is(target error) bool {
+	return target == ErrConnectionLost
+}
+
+func (e *connectionLostError) Unwrap() error {
+	return e.err
+}
+
+// wrapConnectionLost returns err wrapped to match ErrConnectionLost if the
+// connection was lost, and err otherwise.
+func wrapConnectionLost(err error) error {
+	if !isConnectionLost(err) {
+		return err
+	}
+	return &connectionLostError{err: err}
+}
+
+// typedError wraps an error returned by gorm or the driver to also match the
+// gormx error it translates to, keeping its message. The original error
+// remains available through errors.Is and errors.As.
+type typedError struct {
+	typed error
+	err   error
+}
+
+func (e *typedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *typedError) Is(target error) bool {
+	return target == e.typed
+}
+
+func (e *typedError) Unwrap() error {
+	return e.err
+}
+
+// translateError returns err wrapped to match ErrNotFound, ErrDuplicateKey or
+// ErrForeignKeyViolation when it is one of the errors they stand for, and err
+// otherwise.
+func translateError(err error) error {
+	if typed := typedErrorOf(err); typed != nil {
+		return &typedError{typed: typed, err: err}
+	}
+	return err
+}
+
+func typedErrorOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062:
+			return ErrDuplicateKey
+		case 1451, 1452:
+			return ErrForeignKeyViolation
+		}
+		return nil
+	}
+
+	switch sqlState(err) {
+	case "23505":
+		return ErrDuplicateKey
+	case "23503":
+		return ErrForeignKeyViolation
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestErrConnectionLost(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	// simulate the server going away on statements flagged by the test
+	goneAway := &mysql.MySQLError{Number: 2006, Message: "MySQL server has gone away"}
+	cb := db.Callback().Raw()
+	if cb.Get("test:gone_away") == nil {
+		assert.NoError(cb.After("gorm:raw").Before("gormx:after_raw").Register("test:gone_away", func(db *gorm.DB) {
+			if _, ok := db.Get("test:gone_away"); ok {
+				db.Error = goneAway
+			}
+		}))
+	}
+
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+
+	err := tx.Set("test:gone_away", true).Exec("INSERT INTO t1(id) VALUES('abc')").Error
+	assert.ErrorIs(err, gormx.ErrConnectionLost)
+
+	var mysqlErr *mysql.MySQLError
+	assert.True(errors.As(err, &mysqlErr))
+	assert.Equal(uint16(2006), mysqlErr.Number)
+
+	assert.ErrorIs(tx.Err(), gormx.ErrConnectionLost)
+
+	// other errors are left untouched
+	err = tx.Exec("INSERT INTO missing(id) VALUES('abc')").Error
+	assert.Error(err)
+	assert.NotErrorIs(err, gormx.ErrConnectionLost)
+}
+
+func TestTypedErrors(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T1](gx)
+
+	_, err := repo.FindByID(ctx, "missing")
+	assert.ErrorIs(err, gormx.ErrNotFound)
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+	assert.EqualError(err, gorm.ErrRecordNotFound.Error())
+
+	assert.NoError(repo.Create(ctx, &models.T1{ID: "abc"}))
+	err = repo.Create(ctx, &models.T1{ID: "abc"})
+	assert.ErrorIs(err, gormx.ErrDuplicateKey)
+	assert.NotErrorIs(err, gormx.ErrNotFound)
+
+	// the driver error remains available
+	var mysqlErr *mysql.MySQLError
+	if assert.ErrorAs(err, &mysqlErr) {
+		assert.Equal(uint16(1062), mysqlErr.Number)
+	}
+
+	// copying the row again duplicates its key
+	_, err = gx.CopyRows(ctx, "t1", "t1", "")
+	assert.ErrorIs(err, gormx.ErrDuplicateKey)
+
+	_, err = gormx.Raw[models.T1](ctx, gx, "SELECT * FROM missing")
+	assert.Error(err)
+	assert.False(errors.Is(err, gormx.ErrNotFound) || errors.Is(err, gormx.ErrDuplicateKey))
+}
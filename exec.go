@@ -0,0 +1,32 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedRowCount is returned by ExpectAffected when a statement
+// affects another number of rows than expected.
+var ErrUnexpectedRowCount = errors.New("unexpected row count")
+
+// ExpectAffected runs the statement in a savepoint, which is rolled back
+// with ErrUnexpectedRowCount if the statement doesn't affect exactly expected
+// rows. This guards against statements such as an UPDATE missing its WHERE
+// clause.
+func (g *gormx) ExpectAffected(ctx context.Context, expected int64, sql string, args ...any) error {
+	tx := g.BeginTxx(ctx)
+
+	res := tx.WithContext(ctx).Exec(sql, args...)
+	if res.Error != nil {
+		tx.Rollbackx()
+		return translateError(res.Error)
+	}
+
+	if res.RowsAffected != expected {
+		tx.Rollbackx()
+		return fmt.Errorf("%w: expected %d, got %d", ErrUnexpectedRowCount, expected, res.RowsAffected)
+	}
+
+	return tx.Commitx()
+}
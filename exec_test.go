@@ -0,0 +1,33 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_ExpectAffected(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('a'), ('b')")
+
+	err := gx.ExpectAffected(ctx, 1, "UPDATE t1 SET id = CONCAT(id, '_x')")
+	assert.ErrorIs(err, gormx.ErrUnexpectedRowCount)
+
+	err = gx.ExpectAffected(ctx, 1, "UPDATE t1 SET id = 'c' WHERE id = ?", "b")
+	assert.NoError(err)
+
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Order("id").Find(&t1s)
+	assert.Equal([]T1{{ID: "a"}, {ID: "c"}}, t1s)
+}
@@ -0,0 +1,41 @@
+package gormx
+
+import "context"
+
+// WithExplainJSON makes Explain return the plans in JSON, using
+// EXPLAIN FORMAT=JSON on MySQL and EXPLAIN (FORMAT JSON) on Postgres.
+func WithExplainJSON() Option {
+	return func(g *gormx) error {
+		g.explainJSON = true
+		return nil
+	}
+}
+
+// Explain returns the plan of the sql statement, run with args using the
+// active transaction, as returned by the EXPLAIN statement of the dialect:
+// one row per step of the plan, or a single row holding the JSON plan with
+// WithExplainJSON.
+func (g *gormx) Explain(ctx context.Context, sql string, args ...any) ([]map[string]any, error) {
+	var prefix string
+	switch g.dialect() {
+	case "mysql":
+		prefix = "EXPLAIN "
+		if g.explainJSON {
+			prefix = "EXPLAIN FORMAT=JSON "
+		}
+	case "postgres":
+		prefix = "EXPLAIN "
+		if g.explainJSON {
+			prefix = "EXPLAIN (FORMAT JSON) "
+		}
+	case "sqlite":
+		if g.explainJSON {
+			return nil, ErrIncompatibleOption
+		}
+		prefix = "EXPLAIN QUERY PLAN "
+	default:
+		return nil, ErrIncompatibleOption
+	}
+
+	return QueryMaps(ctx, g, prefix+sql, args...)
+}
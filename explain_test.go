@@ -0,0 +1,53 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_Explain(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+
+	plan, err := tx.Explain(ctx, "SELECT * FROM t1 WHERE id = ?", "abc")
+	assert.NoError(err)
+	if assert.NotEmpty(plan) {
+		assert.Contains(plan[0], "select_type")
+	}
+}
+
+func TestGormx_Explain_JSON(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithExplainJSON())
+	defer gx.Close()
+
+	plan, err := gx.Explain(context.Background(), "SELECT * FROM t1")
+	assert.NoError(err)
+	if assert.Len(plan, 1) {
+		assert.Contains(plan[0]["EXPLAIN"], "query_block")
+	}
+}
+
+func TestPostgresExplain(t *testing.T) {
+	assert := assert.New(t)
+	db := createPostgresConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	plan, err := gx.Explain(context.Background(), "SELECT * FROM t1 WHERE id = ?", "abc")
+	assert.NoError(err)
+	if assert.NotEmpty(plan) {
+		assert.Contains(plan[0], "QUERY PLAN")
+	}
+}
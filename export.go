@@ -0,0 +1,59 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"io"
+)
+
+// ExportCSV runs a raw query using the active transaction of gx and streams
+// its results to w as CSV, with a header row of column names. Rows are
+// written as they are read, without buffering the result set. NULL values are
+// written as empty fields. It returns the number of rows written, header
+// excluded.
+func ExportCSV(ctx context.Context, gx Gormx, w io.Writer, query string, args ...any) (int64, error) {
+	rows, err := handle(ctx, gx).Raw(query, args...).Rows()
+	if err != nil {
+		return 0, translateError(err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+
+		for i, v := range values {
+			record[i] = v.String
+		}
+		if err := writer.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, translateError(err)
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
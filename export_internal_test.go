@@ -0,0 +1,8 @@
+package gormx
+
+// DropSavePoints forgets the savepoints of g without resolving them, to
+// simulate a corrupted transaction state in tests.
+func DropSavePoints(g *Transaction) {
+	g.savePointIDs = g.savePointIDs[:0]
+	g.savePointTimes = g.savePointTimes[:0]
+}
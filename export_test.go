@@ -0,0 +1,29 @@
+package gormx_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportCSV(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+	tx.Exec("INSERT INTO t1(id) VALUES('a'), ('b')")
+
+	var buf bytes.Buffer
+	n, err := gormx.ExportCSV(ctx, gx, &buf, "SELECT id, NULL AS missing FROM t1 ORDER BY id")
+	assert.NoError(err)
+	assert.Equal(int64(2), n)
+	assert.Equal("id,missing\na,\nb,\n", buf.String())
+}
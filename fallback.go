@@ -0,0 +1,63 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// FallbackChain reads from an ordered list of Gormx, falling back to the
+// next one when a read fails, e.g. to keep serving reads from a secondary
+// while the primary is down. Writes only go to the primary, the first Gormx
+// of the chain.
+type FallbackChain struct {
+	chain []Gormx
+}
+
+// NewFallbackChain creates a FallbackChain writing to primary and reading
+// from primary, then from each of fallbacks in order.
+func NewFallbackChain(primary Gormx, fallbacks ...Gormx) (*FallbackChain, error) {
+	chain := append([]Gormx{primary}, fallbacks...)
+	for _, gx := range chain {
+		if gx == nil {
+			return nil, ErrInvalidGormDB
+		}
+	}
+
+	return &FallbackChain{chain: chain}, nil
+}
+
+// Primary returns the first Gormx of the chain.
+func (c *FallbackChain) Primary() Gormx {
+	return c.chain[0]
+}
+
+// Read runs fn on the active handle of each Gormx of the chain in order
+// until it succeeds, returning the error of the last one if all fail. A
+// gorm.ErrRecordNotFound, which the others would most likely return too, is
+// returned right away, as is any error once ctx is done.
+func (c *FallbackChain) Read(ctx context.Context, fn func(db *gorm.DB) error) error {
+	var err error
+	for _, gx := range c.chain {
+		if err = c.read(ctx, gx, fn); err == nil {
+			return nil
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *FallbackChain) read(ctx context.Context, gx Gormx, fn func(db *gorm.DB) error) error {
+	db, cancel := readHandle(ctx, gx)
+	defer cancel()
+
+	return fn(db)
+}
+
+// Write runs fn on the active handle of the primary, with no fallback.
+func (c *FallbackChain) Write(ctx context.Context, fn func(db *gorm.DB) error) error {
+	return fn(handle(ctx, c.Primary()))
+}
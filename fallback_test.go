@@ -0,0 +1,49 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFallbackChain(t *testing.T) {
+	assert := assert.New(t)
+
+	primary, _ := gormx.New(createConnection(t))
+	secondary, _ := gormx.New(createConnection(t))
+	defer secondary.Close()
+
+	chain, err := gormx.NewFallbackChain(primary, secondary)
+	if !assert.NoError(err) {
+		return
+	}
+
+	ctx := context.Background()
+	assert.NoError(chain.Write(ctx, func(db *gorm.DB) error {
+		return db.Exec("INSERT INTO t1(id) VALUES('abc')").Error
+	}))
+
+	// the primary is down, the read falls back to the secondary
+	assert.NoError(primary.Close())
+
+	var reads int
+	var t1s []T1
+	err = chain.Read(ctx, func(db *gorm.DB) error {
+		reads++
+		return db.Find(&t1s).Error
+	})
+	assert.NoError(err)
+	assert.Equal(2, reads)
+	assert.Equal([]T1{{ID: "abc"}}, t1s)
+
+	// writes don't fall back
+	assert.Error(chain.Write(ctx, func(db *gorm.DB) error {
+		return db.Exec("INSERT INTO t1(id) VALUES('def')").Error
+	}))
+
+	_, err = gormx.NewFallbackChain(nil)
+	assert.ErrorIs(err, gormx.ErrInvalidGormDB)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"strings"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/rogpeppe/fastuuid"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 var (
@@ -27,6 +31,18 @@ var (
 	// ErrInvalidGormDBConfig is returned when a nil Gorm DB config is used to
 	// initialise Gormx
 	ErrInvalidGormDBConfig = errors.New("invalid Gorm DB config")
+
+	// ErrWrongGoroutine is returned when a transaction is used from another
+	// goroutine than the one that began it, with the goroutine guard enabled.
+	ErrWrongGoroutine = errors.New("transaction used from another goroutine")
+
+	// ErrUnbalancedCommit is returned when Commitx is called more times
+	// than transactions were begun.
+	ErrUnbalancedCommit = errors.New("unbalanced commit")
+
+	// ErrCorruptedTransactionState is returned by Rollbackx when the
+	// transaction counters and savepoints don't match.
+	ErrCorruptedTransactionState = errors.New("corrupted transaction state")
 )
 
 var uuids = fastuuid.MustNewGenerator()
@@ -35,62 +51,239 @@ var uuids = fastuuid.MustNewGenerator()
 type Gormx interface {
 	// Ping tests the underlying sql connection.
 	Ping() error
+	// WaitReady waits until the database is ready.
+	WaitReady(ctx context.Context, opts WaitOptions) error
 	// Close the underlying sql connection.
 	Close() error
+	// CloseContext closes the underlying sql connection, giving up waiting
+	// for it once ctx is done. The Gormx must not be used afterwards.
+	CloseContext(ctx context.Context) error
+	// CloseAndRollback rolls back the open transaction, then closes the
+	// underlying SQL database connection.
+	CloseAndRollback() error
 	// Begin a new transaction.
 	Beginx() *gormx
 	// Begin a new transaction using the provided context and options.
 	// Note that the provided parameters are only used when opening a new transaction,
 	// not on nested ones.
 	BeginTxx(ctx context.Context) *gormx
+	// Begin a new transaction using the provided context, returning any error
+	// raised while beginning it.
+	BeginTxxE(ctx context.Context) (*gormx, error)
+	// Begin a new transaction labelled with name for tracing correlation.
+	BeginTxxNamed(ctx context.Context, name string) *gormx
+	// Begin a new transaction with the given options.
+	BeginTxxOpts(ctx context.Context, opts ...BeginOption) (*gormx, error)
+	// Begin a new transaction following the given propagation mode.
+	BeginWithPropagation(ctx context.Context, mode Propagation) (*gormx, error)
+	// Begin a new transaction pinned to a dedicated connection.
+	BeginTxxOnConn(ctx context.Context) (*gormx, error)
+	// Scope begins a new transaction and returns a function resolving it.
+	Scope(ctx context.Context) (tx *gormx, done func(error) error)
+	// WithIndependentTransaction runs fn in a new transaction unaffected by
+	// the currently open one.
+	WithIndependentTransaction(ctx context.Context, fn func(tx *gormx) error) error
+	// WithoutTransaction runs fn on the underlying Gorm DB, outside of the
+	// currently open transaction.
+	WithoutTransaction(fn func(db *gorm.DB) error) error
+	// ReadSnapshot runs fn in a new read-only transaction seeing a snapshot
+	// of the committed data.
+	ReadSnapshot(ctx context.Context, fn func(tx *gormx) error) error
+	// WithTransactionRetry runs fn in a new transaction, re-running it in a
+	// fresh one on deadlocks and serialization failures.
+	WithTransactionRetry(ctx context.Context, maxRetries int, fn func(tx *gormx) error) error
+	// RetrySavepoint runs fn in a nested transaction, retried on its own on
+	// serialization failures.
+	RetrySavepoint(ctx context.Context, maxRetries int, fn func(tx *gormx) error) error
 	// Rollback the associated transaction.
 	Rollbackx() error
 	// Commit the assiociated transaction.
 	Commitx() error
+	// Checkpoint commits the work done so far in the transaction, keeping
+	// it open.
+	Checkpoint() error
+	// Optimize runs the dialect's maintenance statement on tables.
+	Optimize(ctx context.Context, tables ...string) error
+	// Truncate empties tables.
+	Truncate(ctx context.Context, tables ...string) error
+	// CopyRows copies the rows of a table matching a condition into another.
+	CopyRows(ctx context.Context, fromTable, toTable string, where string, args ...any) (int64, error)
+	// Use registers gorm plugins on the underlying Gorm DB.
+	Use(plugins ...gorm.Plugin) error
+	// RegisterClauseBuilder registers a clause builder on the underlying Gorm DB.
+	RegisterClauseBuilder(name string, builder clause.ClauseBuilder)
 	// Gorm returns the underlying Gorm DB.
 	Gorm() *gorm.DB
+	// WithDB returns a new Gormx with the same configuration running on db.
+	WithDB(db *gorm.DB) (Gormx, error)
+	// Session returns the active handle with the given session options.
+	Session(opts *gorm.Session) *gorm.DB
+	// Config returns a copy of the configuration of the underlying Gorm DB.
+	Config() *gorm.Config
 	// Tx returns the underlying transaction.
 	Tx() *gorm.DB
+	// ExpectAffected runs a statement in a savepoint rolled back unless
+	// it affects exactly expected rows.
+	ExpectAffected(ctx context.Context, expected int64, sql string, args ...any) error
+	// IsPinned reports whether the open transaction holds a dedicated
+	// connection.
+	IsPinned() bool
+	// OpenTransactions returns the number of top-level transactions open.
+	OpenTransactions() int
+	// ExecIn returns the transaction handle for the given nesting depth.
+	ExecIn(savepointDepth int) *gorm.DB
+	// ToSQL returns the SQL statement fn would run, without running it.
+	ToSQL(fn func(*gorm.DB) *gorm.DB) string
+	// Explain returns the plan of a statement.
+	Explain(ctx context.Context, sql string, args ...any) ([]map[string]any, error)
+	// Trace returns the savepoint operations of the current or last
+	// top-level transaction.
+	Trace() []TraceEntry
+	// DurationStats returns percentiles of recent top-level transaction
+	// durations.
+	DurationStats() DurationSummary
+	// TotalRowsAffected returns the number of rows affected by the writes of
+	// the current top-level transaction.
+	TotalRowsAffected() int64
+	// StatementCount returns the number of statements run in the current
+	// top-level transaction.
+	StatementCount() int
+	// Defer registers a function called once the current top-level
+	// transaction resolves.
+	Defer(fn func(committed bool)) error
+	// ActiveSavepoints returns the savepoints of the open nested
+	// transactions.
+	ActiveSavepoints() []SavepointInfo
+	// MarshalState returns the state of the open transaction as JSON.
+	MarshalState() ([]byte, error)
+	// Err returns the error left by the last operation.
+	Err() error
+	// StartKeepalive pings the database periodically until stopped.
+	StartKeepalive(interval time.Duration) (stop func())
+	// ReadFresh runs fn on a replica whose lag is within maxLag,
+	// or on the primary otherwise.
+	ReadFresh(ctx context.Context, maxLag time.Duration, fn func(db *gorm.DB) error) error
 }
 
+// Transaction is the transaction handle returned by the Begin methods.
+// It lets packages outside gormx, such as gormxmock, implement Gormx.
+type Transaction = gormx
+
+// Option to configure Gormx
+type Option func(*gormx) error
+
 // New creates a new Gormx with the given DB.
-func New(gorm *gorm.DB) (Gormx, error) {
+func New(gorm *gorm.DB, options ...Option) (Gormx, error) {
+	gormx, err := newGormx(gorm, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the DSN and the configuration of an existing gorm DB can't be
+	// changed anymore
+	if gormx.rewritesDSN() || gormx.namingStrategy != nil {
+		return nil, ErrIncompatibleOption
+	}
+
+	if err := gormx.checkSchema(); err != nil {
+		return nil, err
+	}
+
+	if err := gormx.startStatsCallback(); err != nil {
+		return nil, err
+	}
+
+	return gormx, nil
+}
+
+func newGormx(gorm *gorm.DB, options ...Option) (*gormx, error) {
 	if gorm == nil {
 		return nil, ErrInvalidGormDB
 	}
 
 	gormx := &gormx{
-		nil,
-		gorm,
-		[]string{},
-		true,
-		0,
-		0,
+		db:                     gorm,
+		savePointEnabled:       true,
+		savePointPrefix:        defaultSavePointPrefix,
+		maxSavePointNameLength: defaultMaxSavepointNameLength,
+		openTransactions:       new(atomic.Int64),
+		durations:              &durationSampler{},
+		defaultCtx:             context.Background(),
+	}
+
+	for _, opt := range options {
+		if err := opt(gormx); err != nil {
+			return nil, err
+		}
 	}
 
+	if gormx.checkSavePoints && !gormx.supportsSavePoints() {
+		return nil, fmt.Errorf("%w: savepoints unsupported by the %s dialector", ErrIncompatibleOption, gorm.Dialector.Name())
+	}
+
+	gormx.resetSavePoints()
+	gormx.detach()
+
+	if err := registerCallbacks(gorm); err != nil {
+		return nil, err
+	}
+
+	gormx.checkNestedTransaction()
+
 	return gormx, nil
 }
 
 // Connect to a database.
-func Connect(dataSourceName string, config *gorm.Config) (Gormx, error) {
+func Connect(dataSourceName string, config *gorm.Config, options ...Option) (Gormx, error) {
 	if config == nil {
 		return nil, ErrInvalidGormDBConfig
 	}
 
+	// the configuration is copied, for the options changing it not to alter
+	// the caller's
+	cfg := *config
+	config = &cfg
+
 	db, err := gorm.Open(mysql.Open(dataSourceName), config)
 	if err != nil {
 		return nil, err
 	}
 
-	gormx, err := New(db)
+	gormx, err := newGormx(db, options...)
+	if err == nil && gormx.rewritesDSN() {
+		// some options are applied through the DSN, so the connection has
+		// to be opened again with it
+		var newDSN string
+		if newDSN, err = gormx.rewriteDSN(dataSourceName); err == nil && newDSN != dataSourceName {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+			if db, err = gorm.Open(mysql.Open(newDSN), config); err != nil {
+				return nil, err
+			}
+			dataSourceName = newDSN
+			gormx, err = newGormx(db, options...)
+		}
+	}
+	if err == nil && gormx.namingStrategy != nil {
+		// no model has been parsed yet with the DB just opened
+		config.NamingStrategy = gormx.namingStrategy
+	}
+	if err == nil {
+		err = gormx.checkDSN(dataSourceName)
+	}
+	if err == nil {
+		err = gormx.checkSchema()
+	}
+	if err == nil {
+		err = gormx.startStatsCallback()
+	}
 	if err != nil {
 		// the connection has been opened within this function, we must close it
 		// on error.
-		db, err := db.DB()
-		if err != nil {
-			return nil, err
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			sqlDB.Close()
 		}
-		db.Close()
 		return nil, err
 	}
 
@@ -99,11 +292,92 @@ func Connect(dataSourceName string, config *gorm.Config) (Gormx, error) {
 
 type gormx struct {
 	*gorm.DB
-	db               *gorm.DB
-	savePointIDs     []string
-	savePointEnabled bool
-	transactionCount int
-	commitCount      int
+	db                *gorm.DB
+	detached          *gorm.DB
+	savePointIDs      []string
+	savePointTimes    []time.Time
+	expectedDepth     int
+	autoSavepoint     bool
+	acquireTimeout    time.Duration
+	ddlGuard          bool
+	explainJSON       bool
+	location          *time.Location
+	applicationName   string
+	captureStack      bool
+	uncheckedTruncate bool
+	idempotencyKeyTTL time.Duration
+	schemaTables      []string
+	namingStrategy    schema.Namer
+	requestIDKey      any
+	requestIDComment  bool
+	maxLifetime       time.Duration
+	readTimeout       time.Duration
+	beginStack        string
+	savePointEnabled  bool
+	checkSavePoints   bool
+	transactionCount  int
+	commitCount       int
+
+	// openTransactions counts the top-level transactions open on g and
+	// its forks.
+	openTransactions *atomic.Int64
+
+	// durations samples the durations of the top-level transactions of g
+	// and its forks.
+	durations *durationSampler
+
+	strictDSN   bool
+	strict      bool
+	strictClose bool
+	singleUse   bool
+	used        bool
+
+	// defaultCtx is used by operations not given a context.
+	defaultCtx context.Context
+
+	savePointPrefix        string
+	maxSavePointNameLength int
+
+	// savePointSQL overrides the savepoint statements when set.
+	savePointSQL *savePointSQL
+
+	// internal is set while gormx runs its own savepoint statements so
+	// they are left out of the transaction stats.
+	internal   bool
+	lastErr    error
+	trace      []TraceEntry
+	stats      TxStats
+	beginTime  time.Time
+	onCommit   []txHook
+	onRollback []txHook
+	tagsFn     func(context.Context) map[string]string
+	requestID  string
+	auditUser  func(context.Context) (string, bool)
+	name       string
+	deferred   []func(committed bool)
+
+	sessionInits  []sessionInit
+	sessionResets []sessionInit
+
+	conn      *sql.Conn
+	txOptions *sql.TxOptions
+
+	// lifetime bounds the current top-level transaction to maxLifetime.
+	lifetime         context.Context
+	lifetimeDeadline time.Time
+	cancelLifetime   context.CancelFunc
+
+	goroutineGuard bool
+	goroutineID    uint64
+
+	keepaliveHook func(err error)
+
+	statsInterval     time.Duration
+	statsCallback     func(sql.DBStats)
+	stopStatsCallback func()
+
+	replicas   []*gorm.DB
+	lagChecker func(ctx context.Context) (time.Duration, error)
 }
 
 func (g *gormx) Ping() error {
@@ -116,7 +390,7 @@ func (g *gormx) Ping() error {
 		return err
 	}
 
-	return db.Ping()
+	return db.PingContext(g.defaultCtx)
 }
 
 // Closes the underlying SQL database connection
@@ -133,86 +407,528 @@ func (g *gormx) Close() error {
 		return err
 	}
 
+	if g.stopStatsCallback != nil {
+		g.stopStatsCallback()
+	}
+
+	openErr := g.checkOpenTransactions()
+	g.closePreparedStmts()
+
 	err = db.Close()
 	if err == nil {
-		g.DB = nil
+		g.detach()
+		err = openErr
 	}
 
 	return err
 }
 
-// Creates a new transaction with a background context
+// CloseAndRollback rolls back the transaction open on g, if any, as a whole,
+// running its rollback hooks, then closes the underlying SQL database
+// connection like Close, so that no transaction is left holding its locks at
+// shutdown. The connection is closed even if the rollback fails, and the
+// rollback error is returned first.
+func (g *gormx) CloseAndRollback() error {
+	var rollbackErr error
+	if g.inTransaction() {
+		g.resetSession()
+		rollbackErr = g.withBeginStack(wrapConnectionLost(g.Rollback().Error))
+		g.end()
+		if hookErr := g.runHooks(g.onRollback); rollbackErr == nil {
+			rollbackErr = hookErr
+		}
+		g.runDeferred(false)
+	}
+
+	if err := g.Close(); rollbackErr == nil {
+		return err
+	}
+	return rollbackErr
+}
+
+// CloseContext closes the underlying SQL database connection like Close, but
+// returns ctx's error if ctx is done before the in-flight queries, which Close
+// waits for, have finished. The connection is still closed in the background,
+// so g must not be used once CloseContext returns, whatever the outcome.
+func (g *gormx) CloseContext(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Creates a new transaction with the default context
 func (g *gormx) Beginx() *gormx {
-	return g.BeginTxx(context.Background())
+	return g.BeginTxx(g.defaultCtx)
 }
 
-// Creates a new transaction with a context
+// Creates a new transaction with a context, or with the default context if
+// ctx is nil. It always returns g: an error raised while beginning is
+// recorded on it, failing its statements and reported by Err, and the
+// transaction must still be rolled back. Use BeginTxxE to get the error back.
 func (g *gormx) BeginTxx(ctx context.Context) *gormx {
-	if g.DB == nil {
+	tx, _ := g.beginRecorded(ctx)
+	return tx
+}
+
+// beginRecorded begins a transaction like BeginTxx, also returning the error
+// it records on g.
+func (g *gormx) beginRecorded(ctx context.Context) (*gormx, error) {
+	tx, err := g.BeginTxxE(ctx)
+	if errors.Is(err, ErrWrongGoroutine) {
+		// the transaction belongs to another goroutine, which the error
+		// must not be recorded on
+		panic(err)
+	}
+	g.mustSucceed(err)
+	if tx == nil {
+		g.failBegin(err)
+	}
+
+	return g, err
+}
+
+// failBegin records err, raised by a begin that returned no transaction, on
+// g. Outside of a transaction, its statements then fail with err rather than
+// ErrNotInTransaction. Within one, the nested transaction is accounted for
+// with an empty savepoint ID, so that the Rollbackx or Commitx resolving it
+// doesn't resolve the outer one instead, and err fails the transaction until
+// then.
+func (g *gormx) failBegin(err error) {
+	g.lastErr = err
+	if !g.inTransaction() {
+		g.detach()
+		g.detached.Error = err
+		return
+	}
+
+	if g.DB.Error != err {
+		g.DB.AddError(err)
+	}
+	g.transactionCount += 1
+	g.pushSavePoint("")
+}
+
+// Creates a new transaction with a context, returning the error raised while
+// beginning it or creating its savepoint, if any. A top-level transaction
+// must still be rolled back on error. A nested one is not begun when its
+// savepoint can't be created: nil is returned along with the error, and the
+// transactions already open are left unchanged.
+func (g *gormx) BeginTxxE(ctx context.Context) (*gormx, error) {
+	return g.beginTxx(ctx, nil)
+}
+
+// beginTxx begins a new transaction, using opts for a new top-level one.
+func (g *gormx) beginTxx(ctx context.Context, opts *sql.TxOptions) (*gormx, error) {
+	ctx, err := g.context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkSpent(); err != nil {
+		return nil, err
+	}
+
+	if !g.inTransaction() && g.acquireTimeout > 0 {
+		// the connection is acquired apart, to bound the wait for it
+		// without bounding the transaction
+		if err := g.beginOnConn(ctx, opts); err != nil {
+			return nil, err
+		}
+	} else if !g.inTransaction() {
 		// new actual transaction
-		db := g.db.WithContext(ctx)
-		g.DB = db.Begin()
+		g.begin(g.db.WithContext(ctx), opts)
+	} else if err := g.checkGoroutine(); err != nil {
+		return nil, err
+	}
+
+	nested := g.transactionCount != g.commitCount
+	prevErr := g.DB.Error
+
+	savePointID := g.newSavePointID()
+	g.internal = true
+	g.DB = g.savePoint(savePointID)
+	g.internal = false
+
+	// a failed nested savepoint leaves the open transactions as they were,
+	// and the error is cleared for them to remain usable
+	if err := g.DB.Error; err != nil && nested {
+		g.DB.Error = prevErr
+		return nil, err
 	}
 
 	g.transactionCount += 1
+	g.pushSavePoint(savePointID)
+	g.addTrace(TraceBegin, savePointID)
 
-	// savepoints name must start with a char and cannot contain dashes (-)
-	savePointID := "sp_" + strings.Replace(uuids.Hex128(), "-", "_", -1)
-	g.savePointIDs = append(g.savePointIDs, savePointID)
-	g.DB = g.SavePoint(savePointID)
+	return g, g.DB.Error
+}
 
-	return g
+// Creates a new transaction on a connection dedicated to it for its whole
+// lifetime, so session state such as user variables and temporary tables
+// persists across its statements. The connection is returned to the pool
+// when the top-level transaction is committed or rolled back.
+func (g *gormx) BeginTxxOnConn(ctx context.Context) (*gormx, error) {
+	ctx, err := g.context(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkSpent(); err != nil {
+		return nil, err
+	}
+
+	if !g.inTransaction() {
+		if err := g.beginOnConn(ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.BeginTxxE(ctx)
+}
+
+// beginOnConn opens a new top-level transaction on a connection dedicated to
+// it, released by end.
+func (g *gormx) beginOnConn(ctx context.Context, opts *sql.TxOptions) error {
+	conn, err := g.acquireConn(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx := g.db.WithContext(ctx)
+	tx.Statement.ConnPool = conn
+	g.conn = conn
+	g.begin(tx, opts)
+	if err := g.DB.Error; err != nil {
+		g.end()
+		return err
+	}
+	return nil
+}
+
+// fork returns a copy of g sharing its configuration, with no transaction.
+func (g *gormx) fork() *gormx {
+	f := *g
+	f.detach()
+	f.savePointIDs = nil
+	f.savePointTimes = nil
+	f.resetSavePoints()
+	f.transactionCount = 0
+	f.commitCount = 0
+	f.conn = nil
+	f.lifetime, f.cancelLifetime = nil, nil
+	f.used = false
+	return &f
+}
+
+// begin opens a new top-level transaction on db, with the given options if
+// not nil.
+func (g *gormx) begin(db *gorm.DB, opts *sql.TxOptions) {
+	if g.maxLifetime > 0 {
+		db = db.WithContext(g.limitLifetime(db.Statement.Context))
+	}
+	if opts != nil {
+		g.DB = db.Begin(opts)
+	} else {
+		g.DB = db.Begin()
+	}
+	g.txOptions = opts
+	g.used = true
+	g.DB.Statement.Settings.Store(txSettingKey, g)
+	g.openTransactions.Add(1)
+	g.stats = TxStats{}
+	if g.tagsFn != nil {
+		g.stats.Tags = g.tagsFn(db.Statement.Context)
+	}
+	g.annotateRequestID(db.Statement.Context)
+	g.lastErr = nil
+	g.trace = nil
+	g.deferred = nil
+	g.beginTime = time.Now()
+	g.name = TransactionName(db.Statement.Context)
+	g.beginStack = ""
+	if g.captureStack {
+		g.beginStack = callerStack()
+	}
+	if g.goroutineGuard {
+		g.goroutineID = goroutineID()
+	}
+	g.initSession()
+}
+
+// detach points the embedded gorm DB, outside of a transaction, to a handle
+// failing every statement with ErrNotInTransaction, so that statements run
+// on g once its transaction is resolved fail cleanly rather than panic on a
+// nil DB.
+func (g *gormx) detach() {
+	db := g.db.Session(&gorm.Session{NewDB: true})
+	db.Error = ErrNotInTransaction
+	g.detached = db
+	g.DB = db
+}
+
+// inTransaction reports whether a transaction is open on g.
+func (g *gormx) inTransaction() bool {
+	return g.DB != nil && g.DB != g.detached
+}
+
+// end releases the resources of the resolved top-level transaction. The
+// counters are reset whatever level resolved it, so that the next
+// transaction begins at the top level.
+func (g *gormx) end() {
+	g.detach()
+	g.endLifetime()
+	g.resetSavePoints()
+	g.transactionCount = 0
+	g.commitCount = 0
+	g.openTransactions.Add(-1)
+	if g.conn != nil {
+		g.conn.Close()
+		g.conn = nil
+	}
 }
 
 // Rollback the transaction to a prior save point, or rollback the whole transaction
 // all together if it is at the top level
 func (g *gormx) Rollbackx() error {
-	if g.DB == nil {
+	if !g.inTransaction() {
 		return ErrNotInTransaction
 	}
 
+	if err := g.checkGoroutine(); err != nil {
+		return err
+	}
+
+	if g.expired() {
+		return g.expire()
+	}
+
+	// the counters and the savepoint stack must agree, or the savepoint
+	// to roll back to is unknown: the whole transaction is rolled back, as
+	// its state can't be trusted anymore
+	if len(g.savePointIDs) == 0 || g.transactionCount <= g.commitCount {
+		err := fmt.Errorf("%w: %d begun, %d committed, %d savepoints",
+			ErrCorruptedTransactionState, g.transactionCount, g.commitCount, len(g.savePointIDs))
+		g.resetSession()
+		rollbackErr := wrapConnectionLost(g.Rollback().Error)
+		g.end()
+		g.runHooks(g.onRollback)
+		g.runDeferred(false)
+		if rollbackErr != nil {
+			return fmt.Errorf("%w: rollback failed: %v", err, rollbackErr)
+		}
+		return err
+	}
+
+	savePointID := g.savePointIDs[len(g.savePointIDs)-1]
+	g.addTrace(TraceRollback, savePointID)
 	g.transactionCount -= 1
 
 	// if we are not at the top level then
-	// just rollback to the previous level
-	if g.transactionCount != g.commitCount {
-		savePointID := g.savePointIDs[len(g.savePointIDs)-1]
-		g.DB = g.RollbackTo(savePointID)
-		g.savePointIDs = g.savePointIDs[:len(g.savePointIDs)-1]
+	// just rollback to the previous level,
+	// unless it joined the current transaction
+	// and has no savepoint to roll back to
+	if g.transactionCount != g.commitCount && savePointID != joinedSavePointID {
+		if savePointID == "" {
+			g.resolveFailedBegin()
+			return nil
+		}
+
+		// any error on the transaction was raised within the nested one, as
+		// its savepoint couldn't have been created otherwise, and is undone
+		// with it. It must be cleared or gorm would refuse to run the
+		// ROLLBACK TO statement, which on Postgres also recovers the
+		// transaction aborted by a failed statement.
+		g.DB.Error = nil
+
+		g.internal = true
+		g.DB = g.rollbackTo(savePointID)
+		g.internal = false
+		g.popSavePoint()
+		g.mustSucceed(g.DB.Error)
 		return nil
 	}
 
-	g.DB = g.Rollback()
-	g.DB = nil
-	return nil
+	g.resetSession()
+	err := g.withBeginStack(wrapConnectionLost(g.Rollback().Error))
+	g.end()
+	g.mustSucceed(err)
+	hookErr := g.runHooks(g.onRollback)
+	g.runDeferred(false)
+	if err != nil {
+		return err
+	}
+	return hookErr
 }
 
 // Commit the transaction to a new save point, or commit the whole transaction all together
 // if it is at the number of nested transaction and commit count is equal
 func (g *gormx) Commitx() error {
-	if g.DB == nil {
+	if !g.inTransaction() {
 		return ErrNotInTransaction
 	}
 
+	if err := g.checkGoroutine(); err != nil {
+		return err
+	}
+
+	if g.expired() {
+		return g.expire()
+	}
+
+	// Committing past the outermost transaction would skew the
+	// counters and commit the whole transaction on a later call
+	if g.commitCount >= g.transactionCount || len(g.savePointIDs) == 0 {
+		return ErrUnbalancedCommit
+	}
+
+	g.addTrace(TraceCommit, g.savePointIDs[len(g.savePointIDs)-1])
 	g.commitCount += 1
 
 	// If this is not the final commit, then
-	// we just continue
+	// the savepoint is released and forgotten,
+	// its work now belonging to the outer level
 	if g.transactionCount != g.commitCount {
-		return nil
+		savePointID := g.savePointIDs[len(g.savePointIDs)-1]
+		if savePointID == "" {
+			return g.resolveFailedBegin()
+		}
+		if savePointID == joinedSavePointID {
+			g.popSavePoint()
+			return nil
+		}
+
+		err := g.release(savePointID)
+		g.popSavePoint()
+		return err
+	}
+
+	g.resetSession()
+	err := g.withBeginStack(wrapConnectionLost(g.Commit().Error))
+	g.end()
+	g.mustSucceed(err)
+	if err != nil {
+		g.runHooks(g.onRollback)
+		g.runDeferred(false)
+		return err
+	}
+
+	err = g.runHooks(g.onCommit)
+	g.runDeferred(true)
+	return err
+}
+
+// Scope begins a new transaction and returns it along with a done function
+// that commits it when called with a nil error, and rolls it back otherwise.
+// done returns the error of the commit, or the error it was called with,
+// mentioning the failure of the rollback if any. If the transaction couldn't
+// begin, done rolls back what was begun and returns the error it was called
+// with, or the begin error if nil. It is meant to be deferred with the
+// function's named error result:
+//
+//	func (s *Service) Do(ctx context.Context) (err error) {
+//		tx, done := s.gx.Scope(ctx)
+//		defer func() { err = done(err) }()
+//		...
+//	}
+func (g *gormx) Scope(ctx context.Context) (tx *gormx, done func(error) error) {
+	tx, beginErr := g.beginRecorded(ctx)
+
+	return tx, func(err error) error {
+		if beginErr != nil {
+			tx.Rollbackx()
+			if err != nil {
+				return err
+			}
+			return beginErr
+		}
+		if err != nil {
+			if rollbackErr := tx.Rollbackx(); rollbackErr != nil {
+				return fmt.Errorf("%w: rollback failed: %v", err, rollbackErr)
+			}
+			return err
+		}
+		return tx.Commitx()
 	}
+}
 
-	g.Commit()
-	g.DB = nil
+// Use registers the plugins on the underlying gorm db. Transactions are
+// sessions of that db, so the plugins apply to them too.
+func (g *gormx) Use(plugins ...gorm.Plugin) error {
+	for _, plugin := range plugins {
+		if err := g.db.Use(plugin); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// RegisterClauseBuilder registers builder to generate the SQL of the clauses
+// named name, e.g. "LIMIT", in place of the one of the dialector, for dialects
+// whose syntax differs from the one of their gorm driver. Like the builders
+// of the dialector, it applies to the transactions too. It must be called
+// before the db is used concurrently.
+func (g *gormx) RegisterClauseBuilder(name string, builder clause.ClauseBuilder) {
+	if g.db.ClauseBuilders == nil {
+		g.db.ClauseBuilders = map[string]clause.ClauseBuilder{}
+	}
+	g.db.ClauseBuilders[name] = builder
+}
+
 // Gorm returns the underlying gorm db.
 func (g *gormx) Gorm() *gorm.DB {
 	return g.db
 }
 
+// Config returns a copy of the configuration the underlying gorm db was
+// opened with, e.g. to check its logger or naming strategy at runtime. It is
+// a shallow copy, meant for inspection: changing it has no effect.
+func (g *gormx) Config() *gorm.Config {
+	config := *g.db.Config
+	return &config
+}
+
 // Tx returns the underlying transaction.
 func (g *gormx) Tx() *gorm.DB {
+	if !g.inTransaction() {
+		return nil
+	}
 	return g.DB
 }
+
+// IsPinned reports whether the transaction open on g holds a connection
+// dedicated to it, as when begun by BeginTxxOnConn, so that session state such
+// as user variables and advisory locks persists across its statements.
+func (g *gormx) IsPinned() bool {
+	return g.inTransaction() && g.conn != nil
+}
+
+// OpenTransactions returns the number of top-level transactions currently
+// open on g, including independent ones. A non-zero value once all work is
+// done reveals a transaction that was never committed nor rolled back.
+func (g *gormx) OpenTransactions() int {
+	return int(g.openTransactions.Load())
+}
+
+// Err returns the error recorded on the underlying transaction or, failing
+// that, the error of the last statement run in it. Outside of a transaction
+// it returns the error of the underlying gorm db or, failing that, the error
+// of a BeginTxx that couldn't begin one.
+func (g *gormx) Err() error {
+	if !g.inTransaction() {
+		if g.db.Error == nil && g.DB.Error != ErrNotInTransaction {
+			// the error of a failed begin
+			return g.DB.Error
+		}
+		return g.db.Error
+	}
+	if g.DB.Error != nil {
+		return g.DB.Error
+	}
+	return g.lastErr
+}
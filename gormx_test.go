@@ -2,6 +2,7 @@ package gormx_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
@@ -11,13 +12,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 const (
 	port = 3366
 )
 
-func createConnection(t *testing.T) *gorm.DB {
+func createConnection(t testing.TB) *gorm.DB {
 	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
 
 	db, err := gorm.Open(mysql.Open(dataSource), &gorm.Config{
@@ -33,11 +36,21 @@ func createConnection(t *testing.T) *gorm.DB {
 		&models.T1{},
 		&models.T2{},
 		&models.T3{},
+		&models.T4{},
+		&models.T5{},
+		&models.T6{},
+		&models.T7{},
+		&models.T8{},
 	)
 
 	db.Exec("truncate t1")
 	db.Exec("truncate t2")
 	db.Exec("truncate t3")
+	db.Exec("truncate t4")
+	db.Exec("truncate t5")
+	db.Exec("truncate t6")
+	db.Exec("truncate t7")
+	db.Exec("truncate t8")
 
 	return db
 }
@@ -145,6 +158,31 @@ func TestConnect(t *testing.T) {
 	}
 }
 
+func TestGormx_Config(t *testing.T) {
+	assert := assert.New(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
+
+	rec := &recordingLogger{}
+	naming := schema.NamingStrategy{TablePrefix: "app_"}
+	gx, err := gormx.Connect(dataSource, &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 rec,
+		NamingStrategy:         naming,
+	})
+	assert.NoError(err)
+	defer gx.Close()
+
+	config := gx.Config()
+	assert.True(config.SkipDefaultTransaction)
+	assert.Same(rec, config.Logger)
+	assert.Equal(naming, config.NamingStrategy)
+
+	// the copy doesn't alter the configuration in use
+	config.SkipDefaultTransaction = false
+	assert.True(gx.Config().SkipDefaultTransaction)
+	assert.True(gx.Gorm().SkipDefaultTransaction)
+}
+
 func TestGormx_Ping(t *testing.T) {
 	assert := assert.New(t)
 
@@ -235,6 +273,107 @@ func TestGormx_Close(t *testing.T) {
 	}
 }
 
+func TestGormx_Close_PreparedStatements(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t).Session(&gorm.Session{PrepareStmt: true})
+	gx, _ := gormx.New(db)
+
+	ctx := context.Background()
+
+	var t1s []T1
+	assert.NoError(gx.Gorm().Find(&t1s).Error)
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES(?)", "abc").Error)
+	assert.NoError(tx.Find(&t1s).Error)
+	assert.NoError(tx.Commitx())
+
+	stmts := db.ConnPool.(*gorm.PreparedStmtDB).Stmts
+	assert.NotEmpty(stmts)
+
+	assert.NoError(gx.Close())
+	assert.Empty(stmts)
+}
+
+func TestWithStrictClose(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	gx, _ := gormx.New(db)
+	tx := gx.BeginTxx(context.Background())
+	gx.BeginTxx(context.Background())
+	assert.NoError(gx.Close())
+	assert.Contains(rec.Messages(), "gormx: closing with 1 transactions still open, 2 savepoints")
+	tx.Rollbackx()
+
+	db = createConnection(t)
+	gx, _ = gormx.New(db, gormx.WithStrictClose())
+	tx = gx.BeginTxx(context.Background())
+	err := gx.Close()
+	assert.ErrorIs(err, gormx.ErrOpenTransactions)
+	assert.ErrorContains(err, "1 transactions, 1 savepoints")
+	tx.Rollbackx()
+
+	// resolved transactions don't fail the close
+	db = createConnection(t)
+	gx, _ = gormx.New(db, gormx.WithStrictClose())
+	tx = gx.BeginTxx(context.Background())
+	assert.NoError(tx.Commitx())
+	assert.NoError(gx.Close())
+}
+
+func TestGormx_CloseContext(t *testing.T) {
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+
+	assert.NoError(t, gx.CloseContext(context.Background()))
+}
+
+func TestGormx_CloseAndRollback(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	other := createConnection(t)
+
+	var rolledBack int
+	gx, _ := gormx.New(db, gormx.WithStrictClose(), gormx.OnRollback(func(stats gormx.TxStats) {
+		rolledBack++
+	}))
+
+	ctx := context.Background()
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('def')").Error)
+
+	// the whole transaction is rolled back, so the strict close succeeds
+	assert.NoError(gx.CloseAndRollback())
+	assert.Equal(1, rolledBack)
+	assert.Zero(gx.OpenTransactions())
+	assert.Error(gx.Ping())
+
+	var t1s []T1
+	other.Find(&t1s)
+	assert.Len(t1s, 0)
+
+	// the next transaction begins at the top level, failing on the closed
+	// connection rather than on a savepoint of the rolled back one
+	data, err := gx.MarshalState()
+	assert.NoError(err)
+	assert.JSONEq(`{"active":false,"dialect":"mysql","depth":0,"commit_count":0,"savepoint_ids":[]}`, string(data))
+	tx, err = gx.BeginTxxE(ctx)
+	assert.Error(err)
+	assert.NotNil(tx)
+	tx.Rollbackx()
+	assert.Zero(gx.OpenTransactions())
+
+	// without a transaction, it closes like Close
+	gx, _ = gormx.New(createConnection(t))
+	assert.NoError(gx.CloseAndRollback())
+	assert.Error(gx.Ping())
+}
+
 type T1 struct {
 	ID string `json:"id" db:"id"`
 }
@@ -274,6 +413,213 @@ func TestSingleCommit(t *testing.T) {
 	}
 }
 
+func TestDoubleCommit(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+	assert.NoError(tx.Commitx())
+
+	err := tx.Commitx()
+	assert.ErrorIs(err, gormx.ErrNotInTransaction)
+	assert.Equal(0, gx.OpenTransactions())
+
+	// The extra commit mustn't affect the next transaction
+	tx = gx.BeginTxx(ctx)
+	tx1 := gx.BeginTxx(ctx)
+	tx1.Exec("INSERT INTO t1(id) VALUES('def')")
+	assert.NoError(tx1.Commitx())
+	assert.NotNil(tx.Tx())
+	assert.NoError(tx.Rollbackx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+}
+
+func TestQueryAfterCommit(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	assert.NotPanics(func() {
+		assert.ErrorIs(tx.Find(&t1s).Error, gormx.ErrNotInTransaction)
+		assert.ErrorIs(tx.Exec("INSERT INTO t1(id) VALUES('def')").Error, gormx.ErrNotInTransaction)
+		assert.ErrorIs(tx.Where("id = ?", "abc").Delete(&T1{}).Error, gormx.ErrNotInTransaction)
+	})
+	assert.Empty(t1s)
+
+	// the statements must not have run outside of the transaction
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	// and the next transaction is unaffected
+	tx = gx.BeginTxx(ctx)
+	assert.NoError(tx.Find(&t1s).Error)
+	assert.NoError(tx.Commitx())
+}
+
+func TestRollbackx_CorruptedState(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var rolledBack int
+	gx, _ := gormx.New(db, gormx.OnRollback(func(gormx.TxStats) { rolledBack++ }))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+	gormx.DropSavePoints(tx)
+
+	var err error
+	assert.NotPanics(func() {
+		err = tx.Rollbackx()
+	})
+	assert.ErrorIs(err, gormx.ErrCorruptedTransactionState)
+	assert.ErrorIs(tx.Commitx(), gormx.ErrNotInTransaction)
+	assert.Equal(0, gx.OpenTransactions())
+	assert.Equal(1, rolledBack)
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	// the next transaction begins at the top level
+	tx = gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('def')")
+	assert.NoError(tx.Commitx())
+
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+}
+
+func TestBeginTxx_NilContext(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	var ctx context.Context
+
+	assert.NotPanics(func() {
+		tx := gx.BeginTxx(ctx)
+		assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+		assert.NoError(tx.Commitx())
+	})
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	strict, _ := gormx.New(db, gormx.WithStrictMode())
+	_, err := strict.BeginTxxE(ctx)
+	assert.ErrorIs(err, gormx.ErrInvalidContext)
+	assert.Equal(0, strict.OpenTransactions())
+}
+
+func TestBeginTxxE_SavepointFailure(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	// the second savepoint is invalid
+	calls := 0
+	gx, _ := gormx.New(db, gormx.WithSavepointSQL(
+		func(name string) string {
+			calls++
+			if calls == 2 {
+				return "SAVEPOINT"
+			}
+			return "SAVEPOINT " + name
+		},
+		func(name string) string { return "ROLLBACK TO SAVEPOINT " + name },
+		nil,
+	))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx, err := gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	savepoints := gx.ActiveSavepoints()
+
+	nested, err := gx.BeginTxxE(ctx)
+	assert.Error(err)
+	assert.Nil(nested)
+	assert.Equal(savepoints, gx.ActiveSavepoints())
+
+	// the outer transaction is still usable
+	nested, err = gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+	assert.Zero(gx.OpenTransactions())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+}
+
+func TestBeginTxx_SavepointFailure(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	// the second savepoint is invalid
+	calls := 0
+	gx, _ := gormx.New(db, gormx.WithSavepointSQL(
+		func(name string) string {
+			calls++
+			if calls == 2 {
+				return "SAVEPOINT"
+			}
+			return "SAVEPOINT " + name
+		},
+		func(name string) string { return "ROLLBACK TO SAVEPOINT " + name },
+		nil,
+	))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+	// BeginTxx returns the transaction with the error recorded on it
+	nested := gx.BeginTxx(ctx)
+	if !assert.NotNil(nested) {
+		return
+	}
+	err := nested.Err()
+	assert.Error(err)
+	assert.ErrorIs(nested.Exec("INSERT INTO t1(id) VALUES('def')").Error, err)
+
+	// resolving it restores the outer transaction
+	assert.NoError(nested.Rollbackx())
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('ghi')").Error)
+	assert.NoError(tx.Commitx())
+	assert.Zero(gx.OpenTransactions())
+
+	var t1s []T1
+	gx.Gorm().Order("id").Find(&t1s)
+	assert.Equal([]T1{{ID: "abc"}, {ID: "ghi"}}, t1s)
+}
+
 func TestSingleRollback(t *testing.T) {
 	db := createConnection(t)
 	gx, _ := gormx.New(db)
@@ -435,3 +781,230 @@ func TestDoubleCommitAndSingleRollbackAndAllRollback(t *testing.T) {
 		t.Errorf("rollback didn't work")
 	}
 }
+
+// A committed nested transaction forgets its savepoint, so that rolling back
+// the transaction enclosing it rolls back to the savepoint of the latter.
+func TestSingleCommitAndParentRollback(t *testing.T) {
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	txService := gx.BeginTxx(ctx)
+
+	txParent := gx.BeginTxx(ctx)
+	txParent.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	txChild := gx.BeginTxx(ctx)
+	txChild.Exec("INSERT INTO t2(id) VALUES('abc')")
+	txChild.Commitx()
+
+	txParent.Rollbackx()
+
+	txService.Exec("INSERT INTO t3(id) VALUES('abc')")
+	txService.Commitx()
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+
+	if len(t1s) != 0 {
+		t.Errorf("rollback didn't work")
+	}
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+
+	if len(t2s) != 0 {
+		t.Errorf("rollback didn't work")
+	}
+
+	var t3s []T3
+	gx.Gorm().Find(&t3s)
+
+	if len(t3s) == 0 {
+		t.Errorf("commit didn't work")
+	}
+}
+
+func TestBeginTxxOnConn(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx, err := gx.BeginTxxOnConn(ctx)
+	assert.NoError(err)
+
+	tx.Exec("SET @gormx_var = 'abc'")
+	tx.Exec("INSERT INTO t1(id) SELECT @gormx_var")
+
+	var value string
+	tx.Raw("SELECT @gormx_var").Scan(&value)
+	assert.Equal("abc", value)
+
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Equal([]T1{{ID: "abc"}}, t1s)
+}
+
+func TestGormx_IsPinned(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	assert.False(gx.IsPinned())
+
+	tx, err := gx.BeginTxxOnConn(ctx)
+	assert.NoError(err)
+	assert.True(tx.IsPinned())
+
+	// nested transactions run on the same connection
+	nested := gx.BeginTxx(ctx)
+	assert.True(nested.IsPinned())
+	assert.NoError(nested.Commitx())
+
+	assert.NoError(tx.Commitx())
+	assert.False(gx.IsPinned())
+
+	tx = gx.BeginTxx(ctx)
+	assert.False(tx.IsPinned())
+	assert.NoError(tx.Rollbackx())
+}
+
+func TestGormx_Err(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	tx := gx.BeginTxx(context.Background())
+	defer tx.Rollbackx()
+
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+	assert.NoError(gx.Err())
+
+	err := tx.Exec("INSERT INTO missing(id) VALUES('abc')").Error
+	assert.Error(err)
+	assert.Equal(err, gx.Err())
+}
+
+func TestGormx_Scope(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	insert := func(table string, fail bool) (err error) {
+		tx, done := gx.Scope(ctx)
+		defer func() { err = done(err) }()
+
+		tx.Exec("INSERT INTO " + table + "(id) VALUES('abc')")
+		if fail {
+			return errors.New("failure")
+		}
+		return nil
+	}
+
+	tx, done := gx.Scope(ctx)
+	assert.NoError(insert("t1", false))
+	assert.Error(insert("t2", true))
+	assert.NoError(done(nil))
+
+	assert.Nil(tx.Tx())
+
+	// the error of the commit is returned
+	tx, done = gx.Scope(ctx)
+	assert.NoError(tx.Commitx())
+	assert.ErrorIs(done(nil), gormx.ErrNotInTransaction)
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+}
+
+func TestGormx_OpenTransactions(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.Equal(0, gx.OpenTransactions())
+
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	assert.Equal(1, gx.OpenTransactions())
+	gx.Commitx()
+	gx.Commitx()
+	assert.Equal(0, gx.OpenTransactions())
+
+	// leaked transaction
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	gx.Commitx()
+	assert.Equal(1, gx.OpenTransactions())
+}
+
+type testPlugin struct {
+	initialized bool
+}
+
+func (p *testPlugin) Name() string {
+	return "gormx:test"
+}
+
+func (p *testPlugin) Initialize(db *gorm.DB) error {
+	p.initialized = true
+	return nil
+}
+
+func TestGormx_Use(t *testing.T) {
+	assert := assert.New(t)
+	gx, _ := gormx.New(createDryRunConnection(t))
+
+	plugin := &testPlugin{}
+	assert.NoError(gx.Use(plugin))
+	assert.True(plugin.initialized)
+
+	// gorm refuses to register the same plugin twice
+	assert.Error(gx.Use(plugin))
+}
+
+func TestGormx_RegisterClauseBuilder(t *testing.T) {
+	assert := assert.New(t)
+	gx, _ := gormx.New(createDryRunConnection(t))
+
+	var invoked int
+	gx.RegisterClauseBuilder("LIMIT", func(c clause.Clause, builder clause.Builder) {
+		invoked++
+		if limit, ok := c.Expression.(clause.Limit); ok && limit.Limit != nil {
+			builder.WriteString("FETCH FIRST " + strconv.Itoa(*limit.Limit) + " ROWS ONLY")
+		}
+	})
+
+	sql := gx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Limit(5).Find(&[]models.T1{})
+	})
+	assert.Equal("SELECT * FROM `t1` FETCH FIRST 5 ROWS ONLY", sql)
+	assert.Equal(1, invoked)
+
+	// statements without the clause don't invoke it
+	gx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]models.T1{})
+	})
+	assert.Equal(1, invoked)
+}
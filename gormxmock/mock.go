@@ -0,0 +1,360 @@
+// Package gormxmock provides an in-memory implementation of gormx.Gormx for
+// testing code that depends on it without a database.
+package gormxmock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mock is a Gormx recording the calls made to it. Transactions are not
+// backed by a database: the Begin methods return Transaction, which is nil
+// unless set, so callers must resolve them through the Mock itself.
+type Mock struct {
+	// PingErr is returned by Ping.
+	PingErr error
+	// CloseErr is returned by Close.
+	CloseErr error
+	// CommitErr is returned by Commitx.
+	CommitErr error
+	// RollbackErr is returned by Rollbackx.
+	RollbackErr error
+	// DB is returned by Gorm and handed to ReadFresh.
+	DB *gorm.DB
+	// Transaction is returned by the Begin methods.
+	Transaction *gormx.Transaction
+
+	mu    sync.Mutex
+	calls []string
+	depth int
+}
+
+var _ gormx.Gormx = (*Mock)(nil)
+
+// New creates a new Mock.
+func New() *Mock {
+	return &Mock{}
+}
+
+// Calls returns the names of the methods called so far, in order.
+func (m *Mock) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// Depth returns the number of open nested transactions.
+func (m *Mock) Depth() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.depth
+}
+
+func (m *Mock) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *Mock) Ping() error {
+	m.record("Ping")
+	return m.PingErr
+}
+
+func (m *Mock) WaitReady(ctx context.Context, opts gormx.WaitOptions) error {
+	m.record("WaitReady")
+	return m.PingErr
+}
+
+func (m *Mock) Close() error {
+	m.record("Close")
+	return m.CloseErr
+}
+
+func (m *Mock) CloseContext(ctx context.Context) error {
+	m.record("CloseContext")
+	return m.CloseErr
+}
+
+func (m *Mock) CloseAndRollback() error {
+	m.record("CloseAndRollback")
+
+	m.mu.Lock()
+	m.depth = 0
+	m.mu.Unlock()
+
+	return m.CloseErr
+}
+
+func (m *Mock) Beginx() *gormx.Transaction {
+	return m.BeginTxx(context.Background())
+}
+
+func (m *Mock) BeginTxx(ctx context.Context) *gormx.Transaction {
+	m.record("BeginTxx")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.depth++
+
+	return m.Transaction
+}
+
+func (m *Mock) BeginTxxE(ctx context.Context) (*gormx.Transaction, error) {
+	return m.BeginTxx(ctx), nil
+}
+
+func (m *Mock) BeginTxxNamed(ctx context.Context, name string) *gormx.Transaction {
+	return m.BeginTxx(ctx)
+}
+
+func (m *Mock) BeginTxxOpts(ctx context.Context, opts ...gormx.BeginOption) (*gormx.Transaction, error) {
+	return m.BeginTxx(ctx), nil
+}
+
+func (m *Mock) BeginWithPropagation(ctx context.Context, mode gormx.Propagation) (*gormx.Transaction, error) {
+	return m.BeginTxx(ctx), nil
+}
+
+func (m *Mock) BeginTxxOnConn(ctx context.Context) (*gormx.Transaction, error) {
+	return m.BeginTxx(ctx), nil
+}
+
+func (m *Mock) Scope(ctx context.Context) (*gormx.Transaction, func(error) error) {
+	tx := m.BeginTxx(ctx)
+
+	return tx, func(err error) error {
+		if err != nil {
+			if rollbackErr := m.Rollbackx(); rollbackErr != nil {
+				return fmt.Errorf("%w: rollback failed: %v", err, rollbackErr)
+			}
+			return err
+		}
+		return m.Commitx()
+	}
+}
+
+func (m *Mock) WithTransactionRetry(ctx context.Context, maxRetries int, fn func(tx *gormx.Transaction) error) error {
+	m.record("WithTransactionRetry")
+
+	tx := m.BeginTxx(ctx)
+	if err := fn(tx); err != nil {
+		m.Rollbackx()
+		return err
+	}
+	return m.Commitx()
+}
+
+func (m *Mock) RetrySavepoint(ctx context.Context, maxRetries int, fn func(tx *gormx.Transaction) error) error {
+	m.record("RetrySavepoint")
+
+	if m.Depth() == 0 {
+		return gormx.ErrNotInTransaction
+	}
+
+	tx := m.BeginTxx(ctx)
+	if err := fn(tx); err != nil {
+		m.Rollbackx()
+		return err
+	}
+	return m.Commitx()
+}
+
+func (m *Mock) ReadSnapshot(ctx context.Context, fn func(tx *gormx.Transaction) error) error {
+	m.record("ReadSnapshot")
+
+	tx := m.BeginTxx(ctx)
+	if err := fn(tx); err != nil {
+		m.Rollbackx()
+		return err
+	}
+	return m.Commitx()
+}
+
+func (m *Mock) WithIndependentTransaction(ctx context.Context, fn func(tx *gormx.Transaction) error) error {
+	m.record("WithIndependentTransaction")
+
+	tx := m.BeginTxx(ctx)
+	if err := fn(tx); err != nil {
+		m.Rollbackx()
+		return err
+	}
+	return m.Commitx()
+}
+
+func (m *Mock) WithoutTransaction(fn func(db *gorm.DB) error) error {
+	m.record("WithoutTransaction")
+	return fn(m.DB)
+}
+
+func (m *Mock) Rollbackx() error {
+	m.record("Rollbackx")
+	return m.resolve(m.RollbackErr)
+}
+
+func (m *Mock) Commitx() error {
+	m.record("Commitx")
+	return m.resolve(m.CommitErr)
+}
+
+func (m *Mock) Checkpoint() error {
+	m.record("Checkpoint")
+	if m.Depth() == 0 {
+		return gormx.ErrNotInTransaction
+	}
+	return m.CommitErr
+}
+
+func (m *Mock) resolve(err error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.depth == 0 {
+		return gormx.ErrNotInTransaction
+	}
+	if err != nil {
+		return err
+	}
+
+	m.depth--
+	return nil
+}
+
+func (m *Mock) ExpectAffected(ctx context.Context, expected int64, sql string, args ...any) error {
+	m.record("ExpectAffected")
+	return nil
+}
+
+func (m *Mock) Optimize(ctx context.Context, tables ...string) error {
+	m.record("Optimize")
+	return nil
+}
+
+func (m *Mock) Truncate(ctx context.Context, tables ...string) error {
+	m.record("Truncate")
+	return nil
+}
+
+func (m *Mock) CopyRows(ctx context.Context, fromTable, toTable string, where string, args ...any) (int64, error) {
+	m.record("CopyRows")
+	return 0, nil
+}
+
+func (m *Mock) Use(plugins ...gorm.Plugin) error {
+	m.record("Use")
+	return nil
+}
+
+func (m *Mock) RegisterClauseBuilder(name string, builder clause.ClauseBuilder) {
+	m.record("RegisterClauseBuilder")
+}
+
+func (m *Mock) Gorm() *gorm.DB {
+	return m.DB
+}
+
+// WithDB returns a new Mock with DB set to db.
+func (m *Mock) WithDB(db *gorm.DB) (gormx.Gormx, error) {
+	m.record("WithDB")
+	if db == nil {
+		return nil, gormx.ErrInvalidGormDB
+	}
+	return &Mock{DB: db}, nil
+}
+
+func (m *Mock) Config() *gorm.Config {
+	if m.DB == nil {
+		return nil
+	}
+	config := *m.DB.Config
+	return &config
+}
+
+// Session returns DB with opts applied, or nil if DB is nil.
+func (m *Mock) Session(opts *gorm.Session) *gorm.DB {
+	if m.DB == nil {
+		return nil
+	}
+	return m.DB.Session(opts)
+}
+
+func (m *Mock) Tx() *gorm.DB {
+	return nil
+}
+
+func (m *Mock) IsPinned() bool {
+	return false
+}
+
+func (m *Mock) ExecIn(savepointDepth int) *gorm.DB {
+	return m.DB
+}
+
+func (m *Mock) OpenTransactions() int {
+	if m.Depth() > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (m *Mock) Trace() []gormx.TraceEntry {
+	return nil
+}
+
+func (m *Mock) TotalRowsAffected() int64 {
+	return 0
+}
+
+func (m *Mock) StatementCount() int {
+	return 0
+}
+
+func (m *Mock) Defer(fn func(committed bool)) error {
+	m.record("Defer")
+	return nil
+}
+
+func (m *Mock) ActiveSavepoints() []gormx.SavepointInfo {
+	return nil
+}
+
+// MarshalState returns the depth of the Mock as JSON.
+func (m *Mock) MarshalState() ([]byte, error) {
+	depth := m.Depth()
+	return json.Marshal(map[string]any{"active": depth > 0, "depth": depth})
+}
+
+func (m *Mock) DurationStats() gormx.DurationSummary {
+	return gormx.DurationSummary{}
+}
+
+func (m *Mock) ToSQL(fn func(*gorm.DB) *gorm.DB) string {
+	m.record("ToSQL")
+	return ""
+}
+
+func (m *Mock) Explain(ctx context.Context, sql string, args ...any) ([]map[string]any, error) {
+	m.record("Explain")
+	return nil, nil
+}
+
+func (m *Mock) Err() error {
+	return nil
+}
+
+func (m *Mock) ReadFresh(ctx context.Context, maxLag time.Duration, fn func(db *gorm.DB) error) error {
+	m.record("ReadFresh")
+	return fn(m.DB)
+}
+
+func (m *Mock) StartKeepalive(interval time.Duration) func() {
+	m.record("StartKeepalive")
+	return func() {}
+}
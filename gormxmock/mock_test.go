@@ -0,0 +1,64 @@
+package gormxmock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/gormxmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock_Calls(t *testing.T) {
+	assert := assert.New(t)
+	m := gormxmock.New()
+
+	ctx := context.Background()
+
+	m.BeginTxx(ctx)
+	m.BeginTxx(ctx)
+	assert.Equal(2, m.Depth())
+	assert.NoError(m.Commitx())
+	assert.NoError(m.Rollbackx())
+	assert.Equal(0, m.Depth())
+	assert.ErrorIs(m.Commitx(), gormx.ErrNotInTransaction)
+
+	assert.Equal([]string{"BeginTxx", "BeginTxx", "Commitx", "Rollbackx", "Commitx"}, m.Calls())
+}
+
+func TestMock_Scope(t *testing.T) {
+	assert := assert.New(t)
+	m := gormxmock.New()
+
+	ctx := context.Background()
+
+	_, done := m.Scope(ctx)
+	assert.NoError(done(nil))
+	failure := errors.New("failure")
+	_, done = m.Scope(ctx)
+	assert.ErrorIs(done(failure), failure)
+
+	assert.Equal([]string{"BeginTxx", "Commitx", "BeginTxx", "Rollbackx"}, m.Calls())
+}
+
+func TestMock_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	pingErr := errors.New("ping")
+	closeErr := errors.New("close")
+	commitErr := errors.New("commit")
+
+	var gx gormx.Gormx = &gormxmock.Mock{
+		PingErr:   pingErr,
+		CloseErr:  closeErr,
+		CommitErr: commitErr,
+	}
+
+	assert.ErrorIs(gx.Ping(), pingErr)
+	assert.ErrorIs(gx.Close(), closeErr)
+
+	gx.Beginx()
+	assert.ErrorIs(gx.Commitx(), commitErr)
+	assert.NoError(gx.Rollbackx())
+}
@@ -0,0 +1,44 @@
+package gormx
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// WithGoroutineGuard makes gormx check that a transaction is only used from
+// the goroutine that began it. Commitx, Rollbackx and the functions beginning
+// a nested transaction with an error result, such as BeginTxxE, return
+// ErrWrongGoroutine when called from another goroutine, and BeginTxx, which
+// has no error result, panics with it.
+func WithGoroutineGuard() Option {
+	return func(g *gormx) error {
+		g.goroutineGuard = true
+		return nil
+	}
+}
+
+// checkGoroutine returns ErrWrongGoroutine if the guard is enabled and the
+// transaction was begun by another goroutine.
+func (g *gormx) checkGoroutine() error {
+	if !g.goroutineGuard || !g.inTransaction() {
+		return nil
+	}
+	if goroutineID() != g.goroutineID {
+		return ErrWrongGoroutine
+	}
+	return nil
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// header of its stack trace ("goroutine 42 [running]:").
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i >= 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
@@ -0,0 +1,47 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithGoroutineGuard(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithGoroutineGuard())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	gx.BeginTxx(ctx)
+
+	errs := make(chan error)
+	go func() {
+		errs <- gx.Commitx()
+	}()
+	assert.ErrorIs(<-errs, gormx.ErrWrongGoroutine)
+
+	go func() {
+		_, err := gormx.Raw[T1](ctx, gx, "SELECT id FROM t1")
+		errs <- err
+	}()
+	assert.ErrorIs(<-errs, gormx.ErrWrongGoroutine)
+
+	go func() {
+		_, err := gx.BeginTxxE(ctx)
+		errs <- err
+	}()
+	assert.ErrorIs(<-errs, gormx.ErrWrongGoroutine)
+
+	panics := make(chan any)
+	go func() {
+		defer func() { panics <- recover() }()
+		gx.BeginTxx(ctx)
+	}()
+	assert.Equal(gormx.ErrWrongGoroutine, <-panics)
+
+	assert.NoError(gx.Rollbackx())
+}
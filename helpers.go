@@ -0,0 +1,68 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// handle returns the active transaction of gx bound to ctx, or its base DB
+// when no transaction is open, or the replica ctx routes reads to with
+// UseReplica.
+func handle(ctx context.Context, gx Gormx) *gorm.DB {
+	if g, ok := gx.(*gormx); ok {
+		if err := g.checkGoroutine(); err != nil {
+			db := g.db.WithContext(ctx)
+			db.AddError(err)
+			return db
+		}
+	}
+
+	if tx := gx.Tx(); tx != nil {
+		return tx.WithContext(ctx)
+	}
+	if g, ok := gx.(*gormx); ok {
+		return g.routeFor(ctx).WithContext(ctx)
+	}
+	return gx.Gorm().WithContext(ctx)
+}
+
+// readHandle returns the handle of gx for a read made with ctx, bounded by
+// the timeout set by WithReadTimeout outside of a transaction if ctx has no
+// deadline. cancel must be called once the read is done.
+func readHandle(ctx context.Context, gx Gormx) (db *gorm.DB, cancel context.CancelFunc) {
+	if g, ok := gx.(*gormx); ok && g.readTimeout > 0 && !g.inTransaction() {
+		if _, ok := ctx.Deadline(); !ok {
+			ctx, cancel = context.WithTimeout(ctx, g.readTimeout)
+			return handle(ctx, gx), cancel
+		}
+	}
+	return handle(ctx, gx), func() {}
+}
+
+// ErrInvalidIdentifier is returned when a table or column name isn't a
+// valid SQL identifier.
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+// validateIdentifiers returns ErrInvalidIdentifier listing the names that
+// aren't valid, optionally schema qualified, identifiers.
+func validateIdentifiers(names ...string) error {
+	var invalid []string
+	for _, name := range names {
+		if !identifierRegexp.MatchString(name) {
+			invalid = append(invalid, strconv.Quote(name))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidIdentifier, strings.Join(invalid, ", "))
+	}
+	return nil
+}
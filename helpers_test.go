@@ -0,0 +1,102 @@
+package gormx_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingLogger is a gorm logger recording every message and statement.
+type recordingLogger struct {
+	mu         sync.Mutex
+	messages   []string
+	statements []string
+}
+
+func (l *recordingLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *recordingLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.record(fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.record(fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.record(fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	sql, _ := fc()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statements = append(l.statements, sql)
+}
+
+func (l *recordingLogger) record(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, msg)
+}
+
+// Statements returns the recorded statements.
+func (l *recordingLogger) Statements() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.statements...)
+}
+
+// Messages returns the recorded messages.
+func (l *recordingLogger) Messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+// createDryRunConnection returns a MySQL gorm DB generating statements
+// without running them, usable without a database server.
+func createDryRunConnection(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "gormx:gormx@tcp(localhost:3366)/gormx",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	return db
+}
+
+// noSavePointDialector hides the savepoint support of the wrapped dialector.
+type noSavePointDialector struct {
+	gorm.Dialector
+}
+
+// createConnectionWithoutSavePoints returns a MySQL gorm DB whose dialector
+// doesn't support savepoints.
+func createConnectionWithoutSavePoints(t *testing.T) *gorm.DB {
+	db := createConnection(t)
+	if sql, err := db.DB(); err == nil {
+		sql.Close()
+	}
+
+	db, err := gorm.Open(noSavePointDialector{db.Dialector}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	return db
+}
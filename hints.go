@@ -0,0 +1,30 @@
+package gormx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/hints"
+)
+
+// OptimizerHint returns a MySQL optimizer hint such as
+// "MAX_EXECUTION_TIME(100)", rendered as /*+ ... */ after the SELECT or
+// UPDATE keyword.
+func OptimizerHint(hint string) clause.Expression {
+	return hints.New(hint)
+}
+
+// PlanHint returns a pg_hint_plan hint such as "SeqScan(t1)", rendered as
+// /*+ ... */ before the SELECT statement.
+func PlanHint(hint string) clause.Expression {
+	h := hints.CommentBefore("select", hint)
+	h.Prefix = "/*+ "
+	return h
+}
+
+// WithHints returns the active transaction of gx, or its base DB, with the
+// given hints applied to the next statement.
+func WithHints(ctx context.Context, gx Gormx, hs ...clause.Expression) *gorm.DB {
+	return handle(ctx, gx).Clauses(hs...)
+}
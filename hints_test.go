@@ -0,0 +1,23 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithHints(t *testing.T) {
+	assert := assert.New(t)
+	gx, _ := gormx.New(createDryRunConnection(t))
+
+	ctx := context.Background()
+
+	var t1s []T1
+	stmt := gormx.WithHints(ctx, gx, gormx.OptimizerHint("MAX_EXECUTION_TIME(100)")).Table("t1").Find(&t1s).Statement
+	assert.Equal("SELECT /*+ MAX_EXECUTION_TIME(100) */ * FROM `t1`", stmt.SQL.String())
+
+	stmt = gormx.WithHints(ctx, gx, gormx.PlanHint("SeqScan(t1)")).Table("t1").Find(&t1s).Statement
+	assert.Equal("/*+ SeqScan(t1) */ SELECT * FROM `t1`", stmt.SQL.String())
+}
@@ -0,0 +1,77 @@
+package gormx
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKey is a key recorded by CreateIdempotent. Its table,
+// gormx_idempotency_keys, must be migrated before use, e.g. with
+// db.AutoMigrate(&gormx.IdempotencyKey{}).
+type IdempotencyKey struct {
+	Key       string `gorm:"primaryKey;size:255"`
+	CreatedAt time.Time
+}
+
+// TableName implements gorm's Tabler.
+func (IdempotencyKey) TableName() string {
+	return "gormx_idempotency_keys"
+}
+
+// WithIdempotencyKeyTTL bounds the time the keys of CreateIdempotent are
+// remembered for: a key recorded more than ttl ago is replaced, and the
+// record created again. Keys are remembered forever by default.
+func WithIdempotencyKeyTTL(ttl time.Duration) Option {
+	return func(g *gormx) error {
+		if ttl <= 0 {
+			return ErrIncompatibleOption
+		}
+		g.idempotencyKeyTTL = ttl
+		return nil
+	}
+}
+
+// CreateIdempotent inserts record using the active transaction of gx, unless
+// key was already used, in which case it returns false without inserting
+// anything. The key is recorded along with the record in a nested
+// transaction, so that either both are inserted or none is, and retrying a
+// create with the same key is safe.
+func CreateIdempotent[T any](ctx context.Context, gx Gormx, key string, record *T) (created bool, err error) {
+	tx, err := gx.BeginTxxE(ctx)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return false, err
+	}
+
+	defer func() {
+		if !created || err != nil {
+			tx.Rollbackx()
+			return
+		}
+		err = tx.Commitx()
+	}()
+
+	db := handle(ctx, tx)
+
+	now := time.Now()
+	if tx.idempotencyKeyTTL > 0 {
+		err := db.Where("created_at < ?", now.Add(-tx.idempotencyKeyTTL)).Delete(&IdempotencyKey{Key: key}).Error
+		if err != nil {
+			return false, translateError(err)
+		}
+	}
+
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&IdempotencyKey{Key: key, CreatedAt: now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, translateError(res.Error)
+	}
+
+	if err := db.Create(record).Error; err != nil {
+		return false, translateError(err)
+	}
+	return true, nil
+}
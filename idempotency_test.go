@@ -0,0 +1,69 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateIdempotent(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	assert.NoError(db.AutoMigrate(&gormx.IdempotencyKey{}))
+	db.Exec("truncate gormx_idempotency_keys")
+
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	created, err := gormx.CreateIdempotent(ctx, tx, "key-1", &models.T1{ID: "a"})
+	assert.NoError(err)
+	assert.True(created)
+
+	// a retry with the same key doesn't insert the record again
+	created, err = gormx.CreateIdempotent(ctx, tx, "key-1", &models.T1{ID: "b"})
+	assert.NoError(err)
+	assert.False(created)
+	assert.NoError(tx.Commitx())
+
+	created, err = gormx.CreateIdempotent(ctx, gx, "key-1", &models.T1{ID: "c"})
+	assert.NoError(err)
+	assert.False(created)
+
+	var t1s []models.T1
+	gx.Gorm().Find(&t1s)
+	assert.Equal([]models.T1{{ID: "a"}}, t1s)
+}
+
+func TestWithIdempotencyKeyTTL(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	assert.NoError(db.AutoMigrate(&gormx.IdempotencyKey{}))
+	db.Exec("truncate gormx_idempotency_keys")
+
+	gx, err := gormx.New(db, gormx.WithIdempotencyKeyTTL(time.Hour))
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	db.Create(&gormx.IdempotencyKey{Key: "expired", CreatedAt: time.Now().Add(-2 * time.Hour)})
+	db.Create(&gormx.IdempotencyKey{Key: "recent", CreatedAt: time.Now().Add(-time.Minute)})
+
+	created, err := gormx.CreateIdempotent(ctx, gx, "expired", &models.T1{ID: "a"})
+	assert.NoError(err)
+	assert.True(created)
+
+	created, err = gormx.CreateIdempotent(ctx, gx, "recent", &models.T1{ID: "b"})
+	assert.NoError(err)
+	assert.False(created)
+
+	_, err = gormx.New(db, gormx.WithIdempotencyKeyTTL(0))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
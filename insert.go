@@ -0,0 +1,89 @@
+package gormx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// serialTypes maps the Postgres serial pseudo-types to their storage type.
+var serialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+}
+
+// InsertIfNotExists inserts record using the active transaction of gx, unless
+// a row matching the non-zero fields of where exists, in a single INSERT ...
+// SELECT ... WHERE NOT EXISTS statement. It reports whether record was
+// inserted.
+//
+// The single statement doesn't make the check and the insert atomic: on
+// Postgres under READ COMMITTED, and on MySQL depending on its gap locks,
+// another transaction can still insert a matching row in between. A unique
+// index on the where columns is required for atomicity, in which case the
+// losing insert fails with ErrDuplicateKey, to be handled like a row that
+// already existed.
+//
+// Like gorm's Create, it leaves out the zero fields of record having a
+// default value, such as auto-increment primary keys, but it doesn't read
+// back the values generated by the database. Like gorm's Delete, it refuses
+// to run without conditions.
+func InsertIfNotExists[T any](ctx context.Context, gx Gormx, record *T, where *T) (inserted bool, err error) {
+	db := handle(ctx, gx)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(record); err != nil {
+		return false, err
+	}
+	castValues := db.Dialector.Name() == "postgres"
+
+	var columns, values, conds []string
+	var vars, condVars []any
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+
+		if v, zero := field.ValueOf(ctx, reflect.ValueOf(record).Elem()); field.Creatable && !(zero && field.HasDefaultValue) {
+			columns = append(columns, stmt.Quote(field.DBName))
+			vars = append(vars, v)
+
+			// Postgres types the values of a SELECT as text, unlike those
+			// of a VALUES clause, so they are cast to the column types
+			value := "?"
+			if castValues {
+				dataType := db.Dialector.DataTypeOf(field)
+				if t, ok := serialTypes[dataType]; ok {
+					dataType = t
+				}
+				value = "CAST(? AS " + dataType + ")"
+			}
+			values = append(values, value)
+		}
+
+		if v, zero := field.ValueOf(ctx, reflect.ValueOf(where).Elem()); !zero {
+			conds = append(conds, stmt.Quote(field.DBName)+" = ?")
+			condVars = append(condVars, v)
+		}
+	}
+
+	if len(conds) == 0 {
+		return false, gorm.ErrMissingWhereClause
+	}
+
+	// MySQL 5.7 only accepts a WHERE clause after a FROM clause
+	from := ""
+	if db.Dialector.Name() == "mysql" {
+		from = " FROM DUAL"
+	}
+
+	table := stmt.Quote(stmt.Table)
+	sql := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") SELECT " + strings.Join(values, ", ") + from +
+		" WHERE NOT EXISTS (SELECT 1 FROM " + table + " WHERE " + strings.Join(conds, " AND ") + ")"
+
+	res := db.Exec(sql, append(vars, condVars...)...)
+	return res.RowsAffected > 0, translateError(res.Error)
+}
@@ -0,0 +1,50 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestInsertIfNotExists(t *testing.T) {
+	connections := map[string]func(testing.TB) *gorm.DB{
+		"mysql":    createConnection,
+		"postgres": createPostgresConnection,
+	}
+
+	for name, connect := range connections {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			db := connect(t)
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			tx := gx.BeginTxx(ctx)
+			inserted, err := gormx.InsertIfNotExists(ctx, tx, &models.T5{Name: "abc"}, &models.T5{Name: "abc"})
+			assert.NoError(err)
+			assert.True(inserted)
+
+			inserted, err = gormx.InsertIfNotExists(ctx, tx, &models.T5{Name: "abc"}, &models.T5{Name: "abc"})
+			assert.NoError(err)
+			assert.False(inserted)
+
+			inserted, err = gormx.InsertIfNotExists(ctx, tx, &models.T5{ID: 10, Name: "abc"}, &models.T5{ID: 10})
+			assert.NoError(err)
+			assert.True(inserted)
+			assert.NoError(tx.Commitx())
+
+			var t5s []models.T5
+			gx.Gorm().Order("id").Find(&t5s)
+			assert.Equal([]models.T5{{ID: 1, Name: "abc"}, {ID: 10, Name: "abc"}}, t5s)
+
+			_, err = gormx.InsertIfNotExists(ctx, gx, &models.T5{Name: "abc"}, &models.T5{})
+			assert.ErrorIs(err, gorm.ErrMissingWhereClause)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BeginOption configures a transaction begun with BeginTxxOpts.
+type BeginOption func(*sql.TxOptions)
+
+// WithIsolation sets the isolation level of the transaction. The drivers
+// apply it when beginning the transaction, e.g. on MySQL by issuing
+// SET TRANSACTION ISOLATION LEVEL right before START TRANSACTION, so it only
+// affects that transaction.
+func WithIsolation(level sql.IsolationLevel) BeginOption {
+	return func(opts *sql.TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+// BeginTxxOpts begins a new transaction like BeginTxxE, configured by opts.
+// The options apply to a new top-level transaction: within a transaction,
+// where they can't be changed anymore, it returns ErrIncompatibleOption.
+func (g *gormx) BeginTxxOpts(ctx context.Context, opts ...BeginOption) (*gormx, error) {
+	txOpts := &sql.TxOptions{}
+	for _, opt := range opts {
+		opt(txOpts)
+	}
+
+	if g.inTransaction() && *txOpts != (sql.TxOptions{}) {
+		return nil, ErrIncompatibleOption
+	}
+
+	return g.beginTxx(ctx, txOpts)
+}
@@ -0,0 +1,64 @@
+package gormx_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_BeginTxxOpts_WithIsolation(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name  string
+		level sql.IsolationLevel
+		// seen is the number of rows committed concurrently seen by the
+		// transaction after its first read
+		seen int
+	}
+
+	testCases := []testCase{
+		{
+			name:  "repeatable read",
+			level: sql.LevelRepeatableRead,
+			seen:  0,
+		},
+		{
+			name:  "read committed",
+			level: sql.LevelReadCommitted,
+			seen:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := createConnection(t)
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			tx, err := gx.BeginTxxOpts(ctx, gormx.WithIsolation(tc.level))
+			assert.NoError(err)
+
+			var t1s []T1
+			assert.NoError(tx.Find(&t1s).Error)
+			assert.Len(t1s, 0)
+
+			// committed outside of the transaction
+			assert.NoError(db.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+			assert.NoError(tx.Find(&t1s).Error)
+			assert.Len(t1s, tc.seen)
+
+			// the isolation level can't change within the transaction
+			_, err = gx.BeginTxxOpts(ctx, gormx.WithIsolation(sql.LevelSerializable))
+			assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+
+			assert.NoError(tx.Commitx())
+		})
+	}
+}
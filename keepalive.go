@@ -0,0 +1,64 @@
+package gormx
+
+import (
+	"sync"
+	"time"
+)
+
+// WithKeepaliveHook sets a function called with the result of every ping
+// made by the keepalive loop, e.g. to feed metrics.
+func WithKeepaliveHook(fn func(err error)) Option {
+	return func(g *gormx) error {
+		g.keepaliveHook = fn
+		return nil
+	}
+}
+
+// StartKeepalive pings the database every interval in a background goroutine,
+// keeping idle connections alive through NAT and firewalls and detecting
+// failures early. Failed pings are logged. The returned function stops the
+// loop and waits for the goroutine to exit.
+func (g *gormx) StartKeepalive(interval time.Duration) (stop func()) {
+	return runEvery(interval, func() {
+		err := g.Ping()
+		if err != nil {
+			g.db.Logger.Error(g.defaultCtx, "gormx: keepalive ping failed: %s", err)
+		}
+		if g.keepaliveHook != nil {
+			g.keepaliveHook(err)
+		}
+	})
+}
+
+// runEvery calls fn every interval in a background goroutine until the
+// returned function is called. Stopping waits for a call of fn in progress
+// to return, and is safe to repeat.
+func runEvery(interval time.Duration, fn func()) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fn()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
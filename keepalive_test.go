@@ -0,0 +1,37 @@
+package gormx_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_StartKeepalive(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	pings := make(chan error, 16)
+	gx, _ := gormx.New(db, gormx.WithKeepaliveHook(func(err error) {
+		pings <- err
+	}))
+	defer gx.Close()
+
+	goroutines := runtime.NumGoroutine()
+
+	stop := gx.StartKeepalive(10 * time.Millisecond)
+
+	select {
+	case err := <-pings:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Errorf("no keepalive ping")
+	}
+
+	stop()
+	stop()
+
+	assert.LessOrEqual(runtime.NumGoroutine(), goroutines)
+}
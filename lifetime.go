@@ -0,0 +1,77 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTransactionExpired is returned by the operations of a transaction rolled
+// back for exceeding the lifetime set by WithMaxTransactionLifetime.
+var ErrTransactionExpired = errors.New("transaction expired")
+
+// WithMaxTransactionLifetime rolls back the top-level transactions open for
+// longer than d, so that code forgetting to resolve them doesn't hold locks
+// indefinitely. The rollback happens in the background once d elapses, as
+// when the context of a transaction is done; the statements, commits and
+// rollbacks attempted afterwards return ErrTransactionExpired.
+func WithMaxTransactionLifetime(d time.Duration) Option {
+	return func(g *gormx) error {
+		if d <= 0 {
+			return ErrIncompatibleOption
+		}
+		g.maxLifetime = d
+		return nil
+	}
+}
+
+// limitLifetime bounds ctx, used to begin a top-level transaction, to the
+// lifetime set by WithMaxTransactionLifetime, if any.
+func (g *gormx) limitLifetime(ctx context.Context) context.Context {
+	if g.maxLifetime <= 0 {
+		return ctx
+	}
+
+	g.lifetimeDeadline = time.Now().Add(g.maxLifetime)
+	g.lifetime, g.cancelLifetime = context.WithDeadline(ctx, g.lifetimeDeadline)
+	return g.lifetime
+}
+
+// endLifetime releases the resources of the lifetime of the resolved
+// transaction.
+func (g *gormx) endLifetime() {
+	if g.cancelLifetime != nil {
+		g.cancelLifetime()
+	}
+	g.lifetime, g.cancelLifetime = nil, nil
+}
+
+// expired reports whether the transaction was rolled back for exceeding its
+// lifetime. An earlier deadline of the context it was begun with takes
+// precedence over the lifetime, in which case it didn't expire.
+func (g *gormx) expired() bool {
+	if g.lifetime == nil || !errors.Is(g.lifetime.Err(), context.DeadlineExceeded) {
+		return false
+	}
+	deadline, _ := g.lifetime.Deadline()
+	return !deadline.Before(g.lifetimeDeadline)
+}
+
+// expiredError returns err marked as caused by the expiry of the
+// transaction, if it expired.
+func (g *gormx) expiredError(err error) error {
+	if err == nil || !g.expired() || errors.Is(err, ErrTransactionExpired) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrTransactionExpired, err)
+}
+
+// expire resolves the expired transaction, already rolled back.
+func (g *gormx) expire() error {
+	g.Rollback()
+	g.end()
+	g.runHooks(g.onRollback)
+	g.runDeferred(false)
+	return fmt.Errorf("%w: open for more than %s", ErrTransactionExpired, g.maxLifetime)
+}
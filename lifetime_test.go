@@ -0,0 +1,67 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxTransactionLifetime(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var rolledBack int
+	gx, err := gormx.New(db,
+		gormx.WithMaxTransactionLifetime(100*time.Millisecond),
+		gormx.OnRollback(func(gormx.TxStats) { rolledBack++ }),
+	)
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+	// the transaction is forgotten past its lifetime
+	time.Sleep(200 * time.Millisecond)
+
+	assert.ErrorIs(nested.Exec("INSERT INTO t2(id) VALUES('abc')").Error, gormx.ErrTransactionExpired)
+	assert.ErrorIs(nested.Commitx(), gormx.ErrTransactionExpired)
+	assert.ErrorIs(tx.Commitx(), gormx.ErrNotInTransaction)
+	assert.Equal(1, rolledBack)
+	assert.Zero(gx.OpenTransactions())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	// transactions resolved in time are unaffected, the expiry of a nested
+	// transaction leaving the next one to begin at the top level
+	tx = gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('def')").Error)
+	assert.NoError(tx.Commitx())
+	assert.Zero(gx.OpenTransactions())
+	assert.Empty(gx.ActiveSavepoints())
+
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	// the deadline of the context of the transaction isn't its expiry
+	deadline, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	tx = gx.BeginTxx(deadline)
+	time.Sleep(200 * time.Millisecond)
+
+	err = tx.Commitx()
+	assert.Error(err)
+	assert.NotErrorIs(err, gormx.ErrTransactionExpired)
+	assert.Equal(2, rolledBack)
+
+	_, err = gormx.New(db, gormx.WithMaxTransactionLifetime(0))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
@@ -0,0 +1,94 @@
+package gormx
+
+import (
+	"context"
+	"strings"
+)
+
+// Optimize runs the maintenance statement of the dialect on tables:
+// OPTIMIZE TABLE on MySQL and VACUUM ANALYZE on Postgres. These can't run in
+// a transaction, so Optimize always uses the underlying gorm db, even while
+// a transaction is open.
+func (g *gormx) Optimize(ctx context.Context, tables ...string) error {
+	if err := validateIdentifiers(tables...); err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	db := g.db.WithContext(ctx)
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = db.Statement.Quote(table)
+	}
+
+	switch g.dialect() {
+	case "mysql":
+		return translateError(db.Exec("OPTIMIZE TABLE " + strings.Join(quoted, ", ")).Error)
+	case "postgres":
+		return translateError(db.Exec("VACUUM ANALYZE " + strings.Join(quoted, ", ")).Error)
+	default:
+		return ErrIncompatibleOption
+	}
+}
+
+// WithUncheckedTruncate makes Truncate disable foreign key checks while
+// truncating on MySQL, so tables referenced by others can be truncated.
+func WithUncheckedTruncate() Option {
+	return func(g *gormx) error {
+		g.uncheckedTruncate = true
+		return nil
+	}
+}
+
+// Truncate empties tables, e.g. to reset a test database. TRUNCATE commits
+// implicitly on MySQL, so like Optimize, it always uses the underlying gorm
+// db, even while a transaction is open.
+func (g *gormx) Truncate(ctx context.Context, tables ...string) error {
+	if err := validateIdentifiers(tables...); err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return nil
+	}
+
+	db := g.db.WithContext(ctx)
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = db.Statement.Quote(table)
+	}
+
+	if g.dialect() != "mysql" {
+		return translateError(db.Exec("TRUNCATE TABLE " + strings.Join(quoted, ", ")).Error)
+	}
+
+	// MySQL truncates a single table per statement, and foreign key checks
+	// are a session setting, so all the statements run on the same connection
+	sqlDB, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	db.Statement.ConnPool = conn
+
+	if g.uncheckedTruncate {
+		if err := db.Exec("SET FOREIGN_KEY_CHECKS = 0").Error; err != nil {
+			return translateError(err)
+		}
+		defer db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+	}
+
+	for _, table := range quoted {
+		if err := db.Exec("TRUNCATE TABLE " + table).Error; err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_Optimize(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.NoError(gx.Optimize(ctx, "t1"))
+
+	err := gx.Optimize(ctx, "t1", "t1; DROP TABLE t2")
+	assert.ErrorIs(err, gormx.ErrInvalidIdentifier)
+	assert.ErrorContains(err, "DROP TABLE")
+}
+
+func TestGormx_Truncate(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithUncheckedTruncate())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	db.Exec("INSERT INTO t1(id) VALUES('abc')")
+	db.Exec("INSERT INTO t2(id) VALUES('abc')")
+	db.Exec("INSERT INTO t3(id) VALUES('abc')")
+
+	assert.NoError(gx.Truncate(ctx, "t1", "t2", "t3"))
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+
+	var t3s []T3
+	gx.Gorm().Find(&t3s)
+	assert.Len(t3s, 0)
+
+	err := gx.Truncate(ctx, "t1", "t2;", "t3 t4")
+	assert.ErrorIs(err, gormx.ErrInvalidIdentifier)
+	assert.ErrorContains(err, `"t2;", "t3 t4"`)
+}
@@ -11,3 +11,33 @@ type T2 struct {
 type T3 struct {
 	ID string `json:"id" db:"id" gorm:"primaryKey"`
 }
+
+type T4 struct {
+	OrgID string `json:"org_id" db:"org_id" gorm:"primaryKey"`
+	ID    string `json:"id" db:"id" gorm:"primaryKey"`
+	Name  string `json:"name" db:"name"`
+}
+
+type T5 struct {
+	ID   uint   `json:"id" db:"id" gorm:"primaryKey;autoIncrement"`
+	Name string `json:"name" db:"name"`
+}
+
+type T6 struct {
+	ID        string `json:"id" db:"id" gorm:"primaryKey"`
+	Name      string `json:"name" db:"name"`
+	CreatedBy string `json:"created_by" db:"created_by"`
+	UpdatedBy string `json:"updated_by" db:"updated_by"`
+}
+
+type T7 struct {
+	ID   string `json:"id" db:"id" gorm:"primaryKey"`
+	Name string `json:"name" db:"name"`
+	T8   T8     `json:"t8" gorm:"foreignKey:T7ID"`
+}
+
+type T8 struct {
+	ID   string `json:"id" db:"id" gorm:"primaryKey"`
+	T7ID string `json:"t7_id" db:"t7_id"`
+	Name string `json:"name" db:"name"`
+}
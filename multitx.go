@@ -0,0 +1,128 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MultiTx coordinates transactions over several Gormx, e.g. on different
+// databases, on a best-effort basis.
+//
+// It is not atomic: the transactions are committed one after the other, so
+// if a commit fails after others succeeded, the committed ones can't be
+// undone. MultiTx then rolls back the remaining transactions and reports
+// which ones were committed in a *MultiTxError, so the caller can compensate.
+// Use XA transactions where atomicity is required.
+type MultiTx struct {
+	txs []Gormx
+}
+
+// MultiTxError reports a partial failure of a MultiTx.
+type MultiTxError struct {
+	// Committed holds the indexes of the transactions that were committed.
+	Committed []int
+	// RolledBack holds the indexes of the transactions that were rolled back.
+	RolledBack []int
+	// Failed is the index of the transaction whose commit failed.
+	Failed int
+	// Err is the error returned by the failed commit.
+	Err error
+	// RollbackErrs holds the errors of the rollbacks that failed, by index
+	// of transaction. These transactions may still be open.
+	RollbackErrs map[int]error
+}
+
+func (e *MultiTxError) Error() string {
+	msg := fmt.Sprintf("commit of transaction %d failed after committing %v: %s", e.Failed, e.Committed, e.Err)
+	if len(e.RollbackErrs) == 0 {
+		return msg
+	}
+
+	failed := make([]int, 0, len(e.RollbackErrs))
+	for i := range e.RollbackErrs {
+		failed = append(failed, i)
+	}
+	sort.Ints(failed)
+
+	rollbacks := make([]string, len(failed))
+	for j, i := range failed {
+		rollbacks[j] = fmt.Sprintf("rollback of transaction %d failed: %s", i, e.RollbackErrs[i])
+	}
+	return msg + "; " + strings.Join(rollbacks, "; ")
+}
+
+// Is reports whether target matches the error of a failed rollback, the
+// error of the failed commit being matched through Unwrap.
+func (e *MultiTxError) Is(target error) bool {
+	for _, err := range e.RollbackErrs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *MultiTxError) Unwrap() error {
+	return e.Err
+}
+
+// BeginMulti begins a transaction on each of gxs. On error, the transactions
+// already begun are rolled back.
+func BeginMulti(ctx context.Context, gxs ...Gormx) (*MultiTx, error) {
+	m := &MultiTx{}
+
+	for _, gx := range gxs {
+		if _, err := gx.BeginTxxE(ctx); err != nil {
+			gx.Rollbackx()
+			m.Rollback()
+			return nil, err
+		}
+		m.txs = append(m.txs, gx)
+	}
+
+	return m, nil
+}
+
+// Commit commits the transactions in order. If a commit fails, the following
+// transactions are rolled back and a *MultiTxError reporting every failure is
+// returned.
+func (m *MultiTx) Commit() error {
+	for i, gx := range m.txs {
+		err := gx.Commitx()
+		if err == nil {
+			continue
+		}
+
+		mErr := &MultiTxError{Failed: i, Err: err}
+		for j := range m.txs[:i] {
+			mErr.Committed = append(mErr.Committed, j)
+		}
+		for j, gx := range m.txs[i+1:] {
+			if err := gx.Rollbackx(); err != nil {
+				if mErr.RollbackErrs == nil {
+					mErr.RollbackErrs = map[int]error{}
+				}
+				mErr.RollbackErrs[i+1+j] = err
+				continue
+			}
+			mErr.RolledBack = append(mErr.RolledBack, i+1+j)
+		}
+		return mErr
+	}
+
+	return nil
+}
+
+// Rollback rolls back all the transactions, returning the first error met.
+func (m *MultiTx) Rollback() error {
+	var firstErr error
+	for _, gx := range m.txs {
+		if err := gx.Rollbackx(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,96 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/gormxmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiTx_Commit(t *testing.T) {
+	assert := assert.New(t)
+
+	gx1, _ := gormx.New(createConnection(t))
+	defer gx1.Close()
+	gx2, _ := gormx.New(createConnection(t))
+	defer gx2.Close()
+
+	m, err := gormx.BeginMulti(context.Background(), gx1, gx2)
+	assert.NoError(err)
+
+	gx1.Tx().Exec("INSERT INTO t1(id) VALUES('abc')")
+	gx2.Tx().Exec("INSERT INTO t2(id) VALUES('abc')")
+
+	assert.NoError(m.Commit())
+
+	var t1s []T1
+	gx2.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx1.Gorm().Find(&t2s)
+	assert.Len(t2s, 1)
+}
+
+func TestMultiTx_Rollback(t *testing.T) {
+	assert := assert.New(t)
+
+	gx1, _ := gormx.New(createConnection(t))
+	defer gx1.Close()
+	gx2, _ := gormx.New(createConnection(t))
+	defer gx2.Close()
+
+	m, err := gormx.BeginMulti(context.Background(), gx1, gx2)
+	assert.NoError(err)
+
+	gx1.Tx().Exec("INSERT INTO t1(id) VALUES('abc')")
+	gx2.Tx().Exec("INSERT INTO t2(id) VALUES('abc')")
+
+	assert.NoError(m.Rollback())
+
+	var t1s []T1
+	gx1.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx2.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+}
+
+func TestMultiTx_Commit_Failures(t *testing.T) {
+	assert := assert.New(t)
+
+	commitErr := errors.New("commit")
+	rollbackErr1 := errors.New("rollback 1")
+	rollbackErr2 := errors.New("rollback 2")
+
+	gxs := []gormx.Gormx{
+		gormxmock.New(),
+		&gormxmock.Mock{CommitErr: commitErr},
+		&gormxmock.Mock{RollbackErr: rollbackErr1},
+		gormxmock.New(),
+		&gormxmock.Mock{RollbackErr: rollbackErr2},
+	}
+
+	m, err := gormx.BeginMulti(context.Background(), gxs...)
+	assert.NoError(err)
+
+	err = m.Commit()
+
+	var mErr *gormx.MultiTxError
+	if assert.ErrorAs(err, &mErr) {
+		assert.Equal([]int{0}, mErr.Committed)
+		assert.Equal([]int{3}, mErr.RolledBack)
+		assert.Equal(1, mErr.Failed)
+		assert.Equal(map[int]error{2: rollbackErr1, 4: rollbackErr2}, mErr.RollbackErrs)
+	}
+
+	assert.ErrorIs(err, commitErr)
+	assert.ErrorIs(err, rollbackErr1)
+	assert.ErrorIs(err, rollbackErr2)
+	assert.EqualError(err, "commit of transaction 1 failed after committing [0]: commit; "+
+		"rollback of transaction 2 failed: rollback 1; rollback of transaction 4 failed: rollback 2")
+}
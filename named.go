@@ -0,0 +1,81 @@
+package gormx
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type transactionNameKey struct{}
+
+// TransactionName returns the name of the transaction begun with
+// BeginTxxNamed that ctx belongs to, if any. Statements run in a named
+// transaction carry its name in their context, so gorm loggers and tracing
+// plugins can use it to correlate them with the business operation.
+func TransactionName(ctx context.Context) string {
+	name, _ := ctx.Value(transactionNameKey{}).(string)
+	return name
+}
+
+// BeginTxxNamed begins a new transaction like BeginTxx, labelled with name
+// for tracing correlation: the name is logged along with the begin, carried
+// by the context of the statements of a top-level transaction, and prefixes
+// the statements logged by Debug.
+func (g *gormx) BeginTxxNamed(ctx context.Context, name string) *gormx {
+	resolved, err := g.context(ctx)
+	if err != nil {
+		// BeginTxx records the error
+		return g.BeginTxx(ctx)
+	}
+
+	ctx = context.WithValue(resolved, transactionNameKey{}, name)
+	g.db.Logger.Info(ctx, "gormx: begin transaction %q", name)
+	return g.BeginTxx(ctx)
+}
+
+// Debug returns the transaction handle, or the gorm DB outside of a
+// transaction, in debug mode. The statements of a named transaction are
+// logged with its name.
+func (g *gormx) Debug() *gorm.DB {
+	if !g.inTransaction() {
+		return g.db.Debug()
+	}
+
+	db := g.DB.Debug()
+	if g.name == "" {
+		return db
+	}
+	return db.Session(&gorm.Session{Logger: namedLogger{Interface: db.Logger, name: g.name}})
+}
+
+// namedLogger prefixes the messages and statements logged by a gorm logger
+// with a transaction name.
+type namedLogger struct {
+	logger.Interface
+	name string
+}
+
+func (l namedLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return namedLogger{Interface: l.Interface.LogMode(level), name: l.name}
+}
+
+func (l namedLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Info(ctx, "[%s] "+msg, append([]interface{}{l.name}, args...)...)
+}
+
+func (l namedLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Warn(ctx, "[%s] "+msg, append([]interface{}{l.name}, args...)...)
+}
+
+func (l namedLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	l.Interface.Error(ctx, "[%s] "+msg, append([]interface{}{l.name}, args...)...)
+}
+
+func (l namedLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	l.Interface.Trace(ctx, begin, func() (string, int64) {
+		sql, rows := fc()
+		return "[" + l.name + "] " + sql, rows
+	}, err)
+}
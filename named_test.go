@@ -0,0 +1,48 @@
+package gormx_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGormx_BeginTxxNamed(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxxNamed(ctx, "checkout")
+	assert.Equal("checkout", gormx.TransactionName(tx.Tx().Statement.Context))
+
+	assert.NoError(tx.Debug().Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(tx.Commitx())
+
+	assert.Contains(rec.Messages(), `gormx: begin transaction "checkout"`)
+
+	found := false
+	for _, stmt := range rec.Statements() {
+		if strings.HasPrefix(stmt, "[checkout] INSERT INTO t1") {
+			found = true
+		}
+	}
+	assert.True(found, "named statement not logged: %v", rec.Statements())
+
+	// the name doesn't outlive the transaction
+	tx = gx.BeginTxx(ctx)
+	assert.Empty(gormx.TransactionName(tx.Tx().Statement.Context))
+	assert.NoError(tx.Rollbackx())
+
+	// a nil context falls back to the default one
+	tx = gx.BeginTxxNamed(nil, "refund")
+	assert.NoError(tx.Err())
+	assert.Equal("refund", gormx.TransactionName(tx.Tx().Statement.Context))
+	assert.NoError(tx.Rollbackx())
+}
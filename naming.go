@@ -0,0 +1,22 @@
+package gormx
+
+import "gorm.io/gorm/schema"
+
+// WithNamingStrategy sets the naming strategy gorm uses to map models to
+// table and column names, e.g. to match a legacy schema. It applies to all
+// the handles of gx, transactions included.
+//
+// gorm caches the schema of a model once parsed, so the strategy is set on
+// the configuration of the DB opened by Connect, before any model is used.
+// The configuration given to Connect is left unchanged. The option can't be
+// used with New, whose gorm DB may be in use already: the strategy must be
+// set in its gorm.Config instead.
+func WithNamingStrategy(ns schema.Namer) Option {
+	return func(g *gormx) error {
+		if ns == nil {
+			return ErrIncompatibleOption
+		}
+		g.namingStrategy = ns
+		return nil
+	}
+}
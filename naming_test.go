@@ -0,0 +1,73 @@
+package gormx_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type legacyNamer struct {
+	schema.NamingStrategy
+}
+
+func (n legacyNamer) TableName(table string) string {
+	if table == "T1" {
+		return "legacy_t1"
+	}
+	return n.NamingStrategy.TableName(table)
+}
+
+func TestWithNamingStrategy(t *testing.T) {
+	assert := assert.New(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
+
+	config := new(gorm.Config)
+	gx, err := gormx.Connect(dataSource, config, gormx.WithNamingStrategy(legacyNamer{}))
+	if !assert.NoError(err) {
+		return
+	}
+	defer gx.Close()
+
+	// the configuration given to Connect is left unchanged
+	assert.Nil(config.NamingStrategy)
+
+	db := gx.Gorm()
+	assert.NoError(db.AutoMigrate(&models.T1{}))
+	db.Exec("truncate legacy_t1")
+
+	ctx := context.Background()
+
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	assert.NoError(gx.Tx().Create(&models.T1{ID: "abc"}).Error)
+	assert.NoError(gx.Commitx())
+	assert.NoError(gx.Commitx())
+
+	var count int64
+	db.Raw("SELECT COUNT(*) FROM legacy_t1").Scan(&count)
+	assert.Equal(int64(1), count)
+
+	var t1s []models.T1
+	assert.NoError(db.Find(&t1s).Error)
+	assert.Len(t1s, 1)
+}
+
+func TestWithNamingStrategy_Invalid(t *testing.T) {
+	db := createConnection(t)
+
+	_, err := gormx.New(db, gormx.WithNamingStrategy(nil))
+	assert.ErrorIs(t, err, gormx.ErrIncompatibleOption)
+
+	// the gorm DB given to New may be in use already
+	_, err = gormx.New(db, gormx.WithNamingStrategy(legacyNamer{}))
+	assert.ErrorIs(t, err, gormx.ErrIncompatibleOption)
+	_, changed := db.Config.NamingStrategy.(legacyNamer)
+	assert.False(t, changed)
+}
@@ -0,0 +1,130 @@
+package gormx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// maxPatchesPerStatement bounds the number of patches BulkPatch applies per
+// UPDATE statement, to keep statements and their placeholders count in check.
+const maxPatchesPerStatement = 500
+
+// Patch is a partial update of the record identified by ID.
+type Patch struct {
+	// ID is the value of the single column primary key of the record.
+	ID any
+	// Changes maps the fields to update, by field or column name, to their
+	// new values.
+	Changes map[string]any
+}
+
+// BulkPatch applies patches to the records of T using the active transaction
+// of gx, and returns the number of rows affected. The patches are applied in
+// a single UPDATE statement per 500 patches, setting each column with a CASE
+// on the primary key, so that different columns of different records are
+// updated at once. When several patches of a record change the same column,
+// the last one wins.
+//
+// It returns ErrCompositeKey when the primary key of T spans several columns,
+// and ErrInvalidIdentifier when changing a field T doesn't have. Note that
+// MySQL only counts the rows actually changed as affected.
+func BulkPatch[T any](ctx context.Context, gx Gormx, patches []Patch) (int64, error) {
+	db := handle(ctx, gx)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return 0, err
+	}
+	if len(stmt.Schema.PrimaryFields) != 1 {
+		return 0, ErrCompositeKey
+	}
+
+	var affected int64
+	for start := 0; start < len(patches); start += maxPatchesPerStatement {
+		end := start + maxPatchesPerStatement
+		if end > len(patches) {
+			end = len(patches)
+		}
+
+		sql, vars, err := buildPatch(stmt, patches[start:end])
+		if err != nil {
+			return affected, err
+		}
+		if sql == "" {
+			continue
+		}
+
+		res := db.Exec(sql, vars...)
+		affected += res.RowsAffected
+		if res.Error != nil {
+			return affected, translateError(res.Error)
+		}
+	}
+
+	return affected, nil
+}
+
+// buildPatch builds the UPDATE statement applying patches to the table of
+// stmt, or an empty one if there is nothing to change.
+func buildPatch(stmt *gorm.Statement, patches []Patch) (string, []any, error) {
+	fields := map[string]*schema.Field{}
+	for _, patch := range patches {
+		for name := range patch.Changes {
+			field := stmt.Schema.LookUpField(name)
+			if field == nil || field.DBName == "" {
+				return "", nil, fmt.Errorf("%w: %s", ErrInvalidIdentifier, name)
+			}
+			fields[field.DBName] = field
+		}
+	}
+	if len(fields) == 0 {
+		return "", nil, nil
+	}
+
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	pk := stmt.Quote(stmt.Schema.PrimaryFields[0].DBName)
+
+	var sql strings.Builder
+	var vars []any
+	sql.WriteString("UPDATE " + stmt.Quote(stmt.Table) + " SET ")
+	for i, column := range columns {
+		field := fields[column]
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		sql.WriteString(stmt.Quote(column) + " = CASE " + pk)
+
+		// the first matching branch of a CASE applies, so the patches are
+		// walked backwards for the last change of a column to win
+		for j := len(patches) - 1; j >= 0; j-- {
+			value, ok := patches[j].Changes[field.Name]
+			if !ok {
+				value, ok = patches[j].Changes[field.DBName]
+			}
+			if ok {
+				sql.WriteString(" WHEN ? THEN ?")
+				vars = append(vars, patches[j].ID, value)
+			}
+		}
+		sql.WriteString(" ELSE " + stmt.Quote(column) + " END")
+	}
+
+	ids := make([]any, len(patches))
+	for i, patch := range patches {
+		ids[i] = patch.ID
+	}
+	sql.WriteString(" WHERE " + pk + " IN ?")
+	vars = append(vars, ids)
+
+	return sql.String(), vars, nil
+}
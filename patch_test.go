@@ -0,0 +1,49 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkPatch(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	db.Create(&[]models.T6{
+		{ID: "a", Name: "a", CreatedBy: "alice"},
+		{ID: "b", Name: "b", CreatedBy: "bob"},
+		{ID: "c", Name: "c", CreatedBy: "carol"},
+	})
+
+	tx := gx.BeginTxx(ctx)
+	affected, err := gormx.BulkPatch[models.T6](ctx, tx, []gormx.Patch{
+		{ID: "a", Changes: map[string]any{"Name": "renamed"}},
+		{ID: "b", Changes: map[string]any{"updated_by": "bob"}},
+		{ID: "a", Changes: map[string]any{"updated_by": "alice", "name": "renamed twice"}},
+	})
+	assert.NoError(err)
+	assert.Equal(int64(2), affected)
+	assert.NoError(tx.Commitx())
+
+	var t6s []models.T6
+	gx.Gorm().Order("id").Find(&t6s)
+	assert.Equal([]models.T6{
+		{ID: "a", Name: "renamed twice", CreatedBy: "alice", UpdatedBy: "alice"},
+		{ID: "b", Name: "b", CreatedBy: "bob", UpdatedBy: "bob"},
+		{ID: "c", Name: "c", CreatedBy: "carol"},
+	}, t6s)
+
+	_, err = gormx.BulkPatch[models.T6](ctx, gx, []gormx.Patch{{ID: "a", Changes: map[string]any{"missing": 1}}})
+	assert.ErrorIs(err, gormx.ErrInvalidIdentifier)
+
+	_, err = gormx.BulkPatch[models.T4](ctx, gx, []gormx.Patch{{ID: "a"}})
+	assert.ErrorIs(err, gormx.ErrCompositeKey)
+}
@@ -0,0 +1,127 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPoolExhausted is returned when no connection could be acquired from the
+// pool within the timeout set by WithAcquireTimeout.
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// PoolConfig configures the connection pool of the underlying sql.DB.
+// Zero fields leave the corresponding setting unchanged.
+type PoolConfig struct {
+	// MaxOpenConns is the maximum number of open connections.
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of idle connections.
+	MaxIdleConns int
+	// ConnMaxLifetime is the maximum time a connection may be reused.
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime is the maximum time a connection may stay idle. Set it
+	// below MySQL's wait_timeout so idle connections are closed by the pool
+	// before the server kills them, which otherwise surfaces as
+	// "driver: bad connection" errors after idle periods.
+	ConnMaxIdleTime time.Duration
+}
+
+// WithPool configures the connection pool of the underlying sql.DB.
+func WithPool(cfg PoolConfig) Option {
+	return func(g *gormx) error {
+		db, err := g.db.DB()
+		if err != nil {
+			return err
+		}
+
+		if cfg.MaxOpenConns > 0 {
+			db.SetMaxOpenConns(cfg.MaxOpenConns)
+		}
+		if cfg.MaxIdleConns > 0 {
+			db.SetMaxIdleConns(cfg.MaxIdleConns)
+		}
+		if cfg.ConnMaxLifetime > 0 {
+			db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+		}
+		if cfg.ConnMaxIdleTime > 0 {
+			db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+		}
+		return nil
+	}
+}
+
+// WithAcquireTimeout bounds the time spent waiting for a free connection when
+// beginning a top-level transaction to d, after which ErrPoolExhausted is
+// returned instead of blocking until a connection frees up.
+//
+// The transaction then runs on the connection acquired, bypassing the
+// ConnPool wrappers set on the gorm DB, e.g. for prepared statements. When no
+// connection is acquired, BeginTxxE returns the error, and BeginTxx records
+// it on the handle it returns, failing its statements and reported by Err.
+func WithAcquireTimeout(d time.Duration) Option {
+	return func(g *gormx) error {
+		if d <= 0 {
+			return ErrIncompatibleOption
+		}
+		g.acquireTimeout = d
+		return nil
+	}
+}
+
+// acquireConn takes a connection from the pool, waiting for it at most the
+// timeout set by WithAcquireTimeout, if any.
+func (g *gormx) acquireConn(ctx context.Context) (*sql.Conn, error) {
+	db, err := g.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if g.acquireTimeout <= 0 {
+		return db.Conn(ctx)
+	}
+
+	// the context only bounds the acquisition, the connection outlives it
+	acquireCtx, cancel := context.WithTimeout(ctx, g.acquireTimeout)
+	defer cancel()
+
+	conn, err := db.Conn(acquireCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: no connection within %s", ErrPoolExhausted, g.acquireTimeout)
+	}
+	return conn, err
+}
+
+// WithStatsCallback calls fn with the statistics of the connection pool every
+// interval, in a background goroutine, e.g. to feed them to a metrics system.
+// The goroutine stops when the Gormx is closed, Close waiting for a call of
+// fn in progress to return, so fn must not block on Close.
+func WithStatsCallback(interval time.Duration, fn func(sql.DBStats)) Option {
+	return func(g *gormx) error {
+		if interval <= 0 || fn == nil {
+			return ErrIncompatibleOption
+		}
+		g.statsInterval = interval
+		g.statsCallback = fn
+		return nil
+	}
+}
+
+// startStatsCallback starts the goroutine set up by WithStatsCallback, if any.
+func (g *gormx) startStatsCallback() error {
+	if g.statsCallback == nil {
+		return nil
+	}
+
+	db, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+
+	fn := g.statsCallback
+	g.stopStatsCallback = runEvery(g.statsInterval, func() {
+		fn(db.Stats())
+	})
+	return nil
+}
@@ -0,0 +1,121 @@
+package gormx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPool_ConnMaxIdleTime(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	gx, err := gormx.New(db, gormx.WithPool(gormx.PoolConfig{
+		MaxIdleConns:    2,
+		ConnMaxIdleTime: 100 * time.Millisecond,
+	}))
+	assert.NoError(err)
+	defer gx.Close()
+
+	assert.NoError(gx.Ping())
+
+	sql, _ := gx.Gorm().DB()
+	assert.NotZero(sql.Stats().Idle)
+
+	// the pool cleaner runs at most once per second
+	time.Sleep(1500 * time.Millisecond)
+
+	stats := sql.Stats()
+	assert.Equal(0, stats.Idle)
+	assert.NotZero(stats.MaxIdleTimeClosed)
+}
+
+func TestWithAcquireTimeout(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	holder, err := gormx.New(db, gormx.WithPool(gormx.PoolConfig{MaxOpenConns: 1}))
+	assert.NoError(err)
+	defer holder.Close()
+
+	gx, err := gormx.New(db, gormx.WithAcquireTimeout(50*time.Millisecond))
+	assert.NoError(err)
+
+	ctx := context.Background()
+
+	// saturate the pool
+	tx := holder.BeginTxx(ctx)
+
+	start := time.Now()
+	_, err = gx.BeginTxxE(ctx)
+	assert.ErrorIs(err, gormx.ErrPoolExhausted)
+	assert.Less(time.Since(start), time.Second)
+	assert.Equal(0, gx.OpenTransactions())
+
+	// BeginTxx returns gx, failing its statements with the error
+	failed := gx.BeginTxx(ctx)
+	if assert.NotNil(failed) {
+		assert.ErrorIs(failed.Err(), gormx.ErrPoolExhausted)
+		assert.ErrorIs(failed.Exec("INSERT INTO t1(id) VALUES('abc')").Error, gormx.ErrPoolExhausted)
+		assert.ErrorIs(failed.Commitx(), gormx.ErrNotInTransaction)
+	}
+
+	// done returns the begin error rather than ErrNotInTransaction
+	_, done := gx.Scope(ctx)
+	assert.ErrorIs(done(nil), gormx.ErrPoolExhausted)
+	_, done = gx.Scope(ctx)
+	assert.EqualError(done(errors.New("failure")), "failure")
+	assert.Equal(0, gx.OpenTransactions())
+
+	assert.NoError(tx.Rollbackx())
+
+	// the connection is available again
+	tx, err = gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	_, err = gormx.New(db, gormx.WithAcquireTimeout(0))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
+
+func TestWithStatsCallback(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	stats := make(chan sql.DBStats, 100)
+	gx, err := gormx.New(db,
+		gormx.WithPool(gormx.PoolConfig{MaxOpenConns: 5}),
+		gormx.WithStatsCallback(10*time.Millisecond, func(s sql.DBStats) {
+			stats <- s
+		}),
+	)
+	assert.NoError(err)
+
+	select {
+	case s := <-stats:
+		assert.Equal(5, s.MaxOpenConnections)
+	case <-time.After(time.Second):
+		assert.Fail("stats callback not called")
+	}
+
+	// no call is made once closed
+	assert.NoError(gx.Close())
+	for len(stats) > 0 {
+		<-stats
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(stats)
+
+	_, err = gormx.New(db, gormx.WithStatsCallback(0, func(sql.DBStats) {}))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
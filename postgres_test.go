@@ -0,0 +1,133 @@
+package gormx_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	postgresPort = 5466
+)
+
+func createPostgresConnection(t testing.TB) *gorm.DB {
+	dataSource := fmt.Sprintf("host=localhost port=%s user=gormx password=gormx dbname=gormx sslmode=disable", strconv.FormatInt(postgresPort, 10))
+
+	db, err := gorm.Open(postgres.Open(dataSource), &gorm.Config{
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Errorf("%s", err)
+		return nil
+	}
+
+	db.AutoMigrate(
+		&models.T1{},
+		&models.T2{},
+		&models.T3{},
+		&models.T5{},
+	)
+
+	db.Exec("truncate t1")
+	db.Exec("truncate t2")
+	db.Exec("truncate t3")
+	db.Exec("truncate t5 restart identity")
+
+	return db
+}
+
+func TestPostgresRollbackAbortedSavePoint(t *testing.T) {
+	assert := assert.New(t)
+	db := createPostgresConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t2(id) VALUES('abc')").Error)
+
+	// the failure, undefined_table, is left on the transaction, which is
+	// now aborted until rolled back to the savepoint
+	err := nested.Exec("INSERT INTO missing(id) VALUES('abc')").Error
+	assert.ErrorContains(err, "42P01")
+	nested.AddError(err)
+	assert.ErrorContains(nested.Exec("INSERT INTO t2(id) VALUES('def')").Error, "42P01")
+
+	assert.NoError(gx.Rollbackx())
+	assert.NoError(tx.Exec("INSERT INTO t3(id) VALUES('abc')").Error)
+	assert.NoError(gx.Commitx())
+
+	var t1s []models.T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []models.T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+
+	var t3s []models.T3
+	gx.Gorm().Find(&t3s)
+	assert.Len(t3s, 1)
+}
+
+func TestPostgresRetrySavepoint(t *testing.T) {
+	assert := assert.New(t)
+	db := createPostgresConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorIs(gx.RetrySavepoint(ctx, 1, func(tx *gormx.Transaction) error {
+		return nil
+	}), gormx.ErrNotInTransaction)
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('outer')").Error)
+
+	var attempts int
+	err := tx.RetrySavepoint(ctx, 3, func(tx *gormx.Transaction) error {
+		attempts++
+		if err := tx.Exec("INSERT INTO t2(id) VALUES(?)", strconv.Itoa(attempts)).Error; err != nil {
+			return err
+		}
+		if attempts == 1 {
+			// simulates a serialization failure, aborting the transaction
+			return tx.Exec("DO $$ BEGIN RAISE EXCEPTION 'conflict' USING ERRCODE = '40001'; END $$").Error
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(2, attempts)
+
+	// other errors aren't retried
+	attempts = 0
+	err = tx.RetrySavepoint(ctx, 3, func(tx *gormx.Transaction) error {
+		attempts++
+		return tx.Exec("INSERT INTO missing(id) VALUES('abc')").Error
+	})
+	assert.Error(err)
+	assert.Equal(1, attempts)
+
+	// the work done before is kept
+	assert.NoError(tx.Commitx())
+
+	var t1s []models.T1
+	gx.Gorm().Find(&t1s)
+	assert.Equal([]models.T1{{ID: "outer"}}, t1s)
+
+	var t2s []models.T2
+	gx.Gorm().Find(&t2s)
+	assert.Equal([]models.T2{{ID: "2"}}, t2s)
+}
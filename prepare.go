@@ -0,0 +1,37 @@
+package gormx
+
+import "gorm.io/gorm"
+
+// preparedStmtDB returns the prepared statement cache of g, if gorm's
+// PrepareStmt mode is enabled.
+func (g *gormx) preparedStmtDB() *gorm.PreparedStmtDB {
+	pool := g.db.ConnPool
+	if p, ok := pool.(*recoveringConnPool); ok {
+		pool = p.ConnPool
+	}
+
+	db, _ := pool.(*gorm.PreparedStmtDB)
+	return db
+}
+
+// closePreparedStmts closes and forgets the statements cached by gorm's
+// PrepareStmt mode. Unlike PreparedStmtDB.Close, which closes them in the
+// background, it waits for them to be closed so they don't outlive the pool.
+func (g *gormx) closePreparedStmts() {
+	db := g.preparedStmtDB()
+	if db == nil {
+		return
+	}
+
+	db.Mux.Lock()
+	defer db.Mux.Unlock()
+
+	for query, stmt := range db.Stmts {
+		// statements still being prepared have no sql.Stmt yet
+		if stmt.Stmt != nil {
+			stmt.Close()
+		}
+		delete(db.Stmts, query)
+	}
+	db.PreparedSQL = db.PreparedSQL[:0]
+}
@@ -0,0 +1,62 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+)
+
+// Propagation defines how a transaction relates to the one currently open,
+// after Spring's propagation behaviours.
+type Propagation int
+
+const (
+	// PropagationRequired joins the current transaction, or begins a new one
+	// if none is open. No savepoint is created for the joined level: its
+	// Commitx leaves the work to the current transaction, and its Rollbackx
+	// rolls back the whole transaction, which the outer levels then find
+	// resolved.
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew always begins a new top-level transaction on
+	// its own connection, which commits or rolls back independently of the
+	// current one.
+	PropagationRequiresNew
+	// PropagationNested begins a savepoint in the current transaction, or a
+	// new transaction if none is open.
+	PropagationNested
+)
+
+// ErrUnsupportedPropagation is returned by BeginWithPropagation for unknown
+// propagation modes.
+var ErrUnsupportedPropagation = errors.New("unsupported propagation")
+
+// BeginWithPropagation begins a transaction following the given propagation
+// mode. The returned transaction must be resolved with Commitx or Rollbackx.
+// Errors raised while beginning are returned as by BeginTxxE.
+func (g *gormx) BeginWithPropagation(ctx context.Context, mode Propagation) (*gormx, error) {
+	switch mode {
+	case PropagationRequired:
+		if g.inTransaction() {
+			return g.join()
+		}
+		return g.BeginTxxE(ctx)
+	case PropagationNested:
+		return g.BeginTxxE(ctx)
+	case PropagationRequiresNew:
+		return g.fork().BeginTxxE(ctx)
+	default:
+		return nil, ErrUnsupportedPropagation
+	}
+}
+
+// join accounts for a nested transaction joining the current one, without a
+// savepoint of its own.
+func (g *gormx) join() (*gormx, error) {
+	if err := g.checkGoroutine(); err != nil {
+		return nil, err
+	}
+
+	g.transactionCount += 1
+	g.pushSavePoint(joinedSavePointID)
+	g.addTrace(TraceBegin, joinedSavePointID)
+	return g, nil
+}
@@ -0,0 +1,137 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_BeginWithPropagation(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name        string
+		mode        gormx.Propagation
+		resolve     func(inner, outer *gormx.Transaction)
+		outerExists bool
+		innerExists bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "required",
+			mode: gormx.PropagationRequired,
+			resolve: func(inner, outer *gormx.Transaction) {
+				assert.NoError(inner.Commitx())
+				assert.NoError(outer.Rollbackx())
+			},
+			outerExists: false,
+			innerExists: false,
+		},
+		{
+			name: "required rollback",
+			mode: gormx.PropagationRequired,
+			resolve: func(inner, outer *gormx.Transaction) {
+				assert.NoError(inner.Rollbackx())
+				assert.ErrorIs(outer.Commitx(), gormx.ErrNotInTransaction)
+			},
+			outerExists: false,
+			innerExists: false,
+		},
+		{
+			name: "requires new",
+			mode: gormx.PropagationRequiresNew,
+			resolve: func(inner, outer *gormx.Transaction) {
+				assert.NoError(inner.Commitx())
+				assert.NoError(outer.Rollbackx())
+			},
+			outerExists: false,
+			innerExists: true,
+		},
+		{
+			name: "nested",
+			mode: gormx.PropagationNested,
+			resolve: func(inner, outer *gormx.Transaction) {
+				assert.NoError(inner.Rollbackx())
+				assert.NoError(outer.Commitx())
+			},
+			outerExists: true,
+			innerExists: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := createConnection(t)
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			outer := gx.BeginTxx(ctx)
+			outer.Exec("INSERT INTO t1(id) VALUES('outer')")
+
+			inner, err := gx.BeginWithPropagation(ctx, tc.mode)
+			assert.NoError(err)
+			inner.Exec("INSERT INTO t2(id) VALUES('inner')")
+
+			tc.resolve(inner, outer)
+
+			var t1s []T1
+			gx.Gorm().Find(&t1s)
+			assert.Equal(tc.outerExists, len(t1s) == 1)
+
+			var t2s []T2
+			gx.Gorm().Find(&t2s)
+			assert.Equal(tc.innerExists, len(t2s) == 1)
+
+			// the next transaction begins and commits at the top level
+			tx := gx.BeginTxx(ctx)
+			assert.NoError(tx.Exec("INSERT INTO t3(id) VALUES('next')").Error)
+			assert.NoError(tx.Commitx())
+			assert.Zero(gx.OpenTransactions())
+
+			var t3s []T3
+			gx.Gorm().Find(&t3s)
+			assert.Len(t3s, 1)
+		})
+	}
+}
+
+func TestGormx_BeginWithPropagation_Unsupported(t *testing.T) {
+	gx, _ := gormx.New(createDryRunConnection(t))
+
+	_, err := gx.BeginWithPropagation(context.Background(), gormx.Propagation(42))
+	assert.ErrorIs(t, err, gormx.ErrUnsupportedPropagation)
+}
+
+func TestGormx_BeginWithPropagation_Failure(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	// the savepoint of the nested transaction is invalid
+	calls := 0
+	gx, _ := gormx.New(db, gormx.WithSavepointSQL(
+		func(name string) string {
+			calls++
+			if calls == 2 {
+				return "SAVEPOINT"
+			}
+			return "SAVEPOINT " + name
+		},
+		func(name string) string { return "ROLLBACK TO SAVEPOINT " + name },
+		nil,
+	))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+
+	nested, err := gx.BeginWithPropagation(ctx, gormx.PropagationNested)
+	assert.Error(err)
+	assert.Nil(nested)
+}
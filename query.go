@@ -0,0 +1,57 @@
+package gormx
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Raw runs a raw query using the active transaction of gx and scans the
+// results into a slice of T. A query returning no rows yields an empty slice.
+func Raw[T any](ctx context.Context, gx Gormx, sql string, args ...any) ([]T, error) {
+	db, cancel := readHandle(ctx, gx)
+	defer cancel()
+
+	result := []T{}
+	if err := db.Raw(sql, args...).Scan(&result).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return result, nil
+}
+
+// QueryMaps runs a raw query using the active transaction of gx and returns
+// each row as a map of column names to values. []byte values, which the
+// MySQL driver returns for text columns, are converted to strings.
+func QueryMaps(ctx context.Context, gx Gormx, sql string, args ...any) ([]map[string]any, error) {
+	db, cancel := readHandle(ctx, gx)
+	defer cancel()
+
+	result := []map[string]any{}
+	if err := db.Raw(sql, args...).Scan(&result).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	for _, row := range result {
+		for k, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ToSQL returns the SQL statement, with its arguments inlined, that fn would
+// run on the active handle of g, without running it. fn runs on a DryRun
+// session, e.g.:
+//
+//	sql := gx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+//		return tx.Where("id = ?", id).Find(&[]T1{})
+//	})
+func (g *gormx) ToSQL(fn func(*gorm.DB) *gorm.DB) string {
+	if g.inTransaction() {
+		return g.DB.ToSQL(fn)
+	}
+	return g.db.ToSQL(fn)
+}
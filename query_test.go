@@ -0,0 +1,88 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRaw(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	t1s, err := gormx.Raw[models.T1](ctx, gx, "SELECT id FROM t1")
+	assert.NoError(err)
+	assert.NotNil(t1s)
+	assert.Len(t1s, 0)
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	// the uncommitted row is only visible through the transaction
+	t1s, err = gormx.Raw[models.T1](ctx, gx, "SELECT id FROM t1 WHERE id = ?", "abc")
+	assert.NoError(err)
+	assert.Equal([]models.T1{{ID: "abc"}}, t1s)
+}
+
+func TestQueryMaps(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	rows, err := gormx.QueryMaps(ctx, gx, "SELECT id FROM t1")
+	assert.NoError(err)
+	assert.Equal([]map[string]any{{"id": "abc"}}, rows)
+}
+
+func TestGormx_ToSQL(t *testing.T) {
+	assert := assert.New(t)
+	db := createDryRunConnection(t)
+	gx, _ := gormx.New(db)
+
+	sql := gx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ?", "abc").Find(&[]models.T1{})
+	})
+	assert.Equal("SELECT * FROM `t1` WHERE id = 'abc'", sql)
+}
+
+func TestGormx_ToSQL_InTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var committed []gormx.TxStats
+	gx, _ := gormx.New(db, gormx.OnCommit(func(stats gormx.TxStats) {
+		committed = append(committed, stats)
+	}))
+	defer gx.Close()
+
+	tx := gx.BeginTxx(context.Background())
+	sql := tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Create(&models.T1{ID: "abc"})
+	})
+	assert.Equal("INSERT INTO `t1` (`id`) VALUES ('abc')", sql)
+	assert.NoError(tx.Commitx())
+
+	// the statement isn't run nor accounted for
+	var t1s []models.T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+	if assert.Len(committed, 1) {
+		assert.Zero(committed[0].Statements)
+	}
+}
@@ -0,0 +1,73 @@
+package gormx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultWaitInterval is the default interval between two readiness checks
+// of WaitReady.
+const defaultWaitInterval = 500 * time.Millisecond
+
+// WaitOptions configures the readiness checks of WaitReady.
+type WaitOptions struct {
+	// Interval is the time between two checks. It defaults to 500ms.
+	Interval time.Duration
+	// Query, if set, is a sentinel query that must run without error, e.g.
+	// "SELECT 1 FROM schema_migrations LIMIT 1" to wait for migrations.
+	Query string
+	// Check, if set, is a custom readiness check that must return nil.
+	Check func(ctx context.Context, db *gorm.DB) error
+}
+
+// WaitReady waits until the database is ready, polling it until it answers
+// pings and the checks of opts pass. It returns the context's error, along
+// with the last check failure, if ctx is done first.
+func (g *gormx) WaitReady(ctx context.Context, opts WaitOptions) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := g.checkReady(ctx, opts)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: last check failed: %v", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *gormx) checkReady(ctx context.Context, opts WaitOptions) error {
+	db, err := g.db.DB()
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	if opts.Query != "" {
+		rows, err := g.db.WithContext(ctx).Raw(opts.Query).Rows()
+		if err != nil {
+			return err
+		}
+		rows.Close()
+	}
+
+	if opts.Check != nil {
+		return opts.Check(ctx, g.db.WithContext(ctx))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGormx_WaitReady(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	polls := 0
+	err := gx.WaitReady(ctx, gormx.WaitOptions{
+		Interval: 10 * time.Millisecond,
+		Query:    "SELECT 1 FROM t1 LIMIT 1",
+		Check: func(ctx context.Context, db *gorm.DB) error {
+			polls++
+			if polls < 3 {
+				return errors.New("not ready")
+			}
+			return nil
+		},
+	})
+	assert.NoError(err)
+	assert.Equal(3, polls)
+}
+
+func TestGormx_WaitReady_Timeout(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := gx.WaitReady(ctx, gormx.WaitOptions{
+		Interval: 10 * time.Millisecond,
+		Query:    "SELECT 1 FROM missing_migrations",
+	})
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.ErrorContains(err, "missing_migrations")
+}
@@ -0,0 +1,78 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// WithConnectionRecovery retries the reads run outside of a transaction once
+// when they fail because the connection they used was stale, as happens after
+// MySQL closed an idle connection, and the driver reports it with an
+// "invalid connection" error. database/sql already retries on "driver: bad
+// connection" errors, which guarantee nothing was sent.
+//
+// Only SELECT and SHOW statements are retried: the driver may report an
+// invalid connection after a statement reached the server, so retrying a
+// write could apply it twice. Statements run in a transaction are never
+// retried.
+func WithConnectionRecovery() Option {
+	return func(g *gormx) error {
+		pool := g.db.ConnPool
+		if _, ok := pool.(gorm.TxBeginner); !ok {
+			return ErrIncompatibleOption
+		}
+
+		db := g.db.WithContext(g.db.Statement.Context)
+		db.Config.ConnPool = &recoveringConnPool{pool}
+		db.Statement.ConnPool = db.Config.ConnPool
+		g.db = db
+		return nil
+	}
+}
+
+// recoveringConnPool retries reads once on stale connection errors.
+type recoveringConnPool struct {
+	gorm.ConnPool
+}
+
+func (p *recoveringConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := p.ConnPool.QueryContext(ctx, query, args...)
+	if errors.Is(err, mysql.ErrInvalidConn) && isRead(query) {
+		return p.ConnPool.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+// isRead reports whether query is a SELECT or SHOW statement, which can be
+// run again safely. A Postgres data-modifying WITH isn't considered a read.
+func isRead(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "SHOW":
+		return true
+	}
+	return false
+}
+
+func (p *recoveringConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return p.ConnPool.(gorm.TxBeginner).BeginTx(ctx, opts)
+}
+
+func (p *recoveringConnPool) GetDBConn() (*sql.DB, error) {
+	if connector, ok := p.ConnPool.(gorm.GetDBConnector); ok {
+		return connector.GetDBConn()
+	}
+	if db, ok := p.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
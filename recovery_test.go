@@ -0,0 +1,92 @@
+package gormx_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	gormmysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// staleConnPool fails the first write and the first read with an invalid
+// connection error.
+type staleConnPool struct {
+	*sql.DB
+	execFailed  bool
+	queryFailed bool
+}
+
+func (p *staleConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !p.execFailed {
+		p.execFailed = true
+		return nil, mysql.ErrInvalidConn
+	}
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+func (p *staleConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !p.queryFailed {
+		p.queryFailed = true
+		return nil, mysql.ErrInvalidConn
+	}
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+func (p *staleConnPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+func TestWithConnectionRecovery(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name       string
+		options    []gormx.Option
+		assertions func(error)
+	}
+
+	testCases := []testCase{
+		{
+			name: "without recovery",
+			assertions: func(err error) {
+				assert.ErrorIs(err, mysql.ErrInvalidConn)
+			},
+		},
+		{
+			name:    "with recovery",
+			options: []gormx.Option{gormx.WithConnectionRecovery()},
+			assertions: func(err error) {
+				assert.NoError(err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, _ := createConnection(t).DB()
+			pool := &staleConnPool{DB: sqlDB}
+
+			db, err := gorm.Open(gormmysql.New(gormmysql.Config{
+				Conn:                      pool,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{})
+			assert.NoError(err)
+
+			gx, err := gormx.New(db, tc.options...)
+			assert.NoError(err)
+			defer gx.Close()
+
+			var ids []string
+			err = gx.Gorm().Raw("SELECT id FROM t1").Scan(&ids).Error
+			tc.assertions(err)
+
+			// writes may have reached the server, so they are never retried
+			err = gx.Gorm().Exec("INSERT INTO t1(id) VALUES('abc')").Error
+			assert.ErrorIs(err, mysql.ErrInvalidConn)
+		})
+	}
+}
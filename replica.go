@@ -0,0 +1,101 @@
+package gormx
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WithReplicas registers read replicas of the primary DB.
+func WithReplicas(replicas ...*gorm.DB) Option {
+	return func(g *gormx) error {
+		for _, r := range replicas {
+			if r == nil {
+				return ErrInvalidGormDB
+			}
+		}
+
+		g.replicas = append(g.replicas, replicas...)
+		return nil
+	}
+}
+
+// WithLagChecker sets the function used by ReadFresh to measure the
+// replication lag of the replicas.
+func WithLagChecker(fn func(ctx context.Context) (time.Duration, error)) Option {
+	return func(g *gormx) error {
+		g.lagChecker = fn
+		return nil
+	}
+}
+
+// route is the DB a context routes reads to.
+type route int
+
+const (
+	routePrimary route = iota
+	routeReplica
+)
+
+type routeKey struct{}
+
+// UsePrimary returns a copy of ctx routing the reads made with it outside of
+// a transaction to the primary, e.g. to read a write just made in another
+// session. It takes precedence over the lag check of ReadFresh.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, routePrimary)
+}
+
+// UseReplica returns a copy of ctx routing the reads made with it outside of
+// a transaction to the first replica, whatever its lag, or to the primary if
+// there is none. It applies to ReadFresh and to the query helpers, such as
+// Raw and Repository, which otherwise read from the primary. Like a read
+// clause of gorm's dbresolver, it routes any statement made with ctx, so it
+// must not be used for writes.
+func UseReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, routeReplica)
+}
+
+// routeFor returns the DB the statements made with ctx outside of a
+// transaction are routed to by the query helpers.
+func (g *gormx) routeFor(ctx context.Context) *gorm.DB {
+	route, _ := ctx.Value(routeKey{}).(route)
+	return g.routed(route)
+}
+
+func (g *gormx) routed(route route) *gorm.DB {
+	if route == routeReplica && len(g.replicas) > 0 {
+		return g.replicas[0]
+	}
+	return g.db
+}
+
+// ReadFresh runs fn against a replica if its replication lag is within
+// maxLag, or against the primary otherwise. The primary is also used when
+// no replica or lag checker is configured, or when the lag can't be measured.
+// Inside a transaction fn always runs on the transaction, so it sees its
+// uncommitted writes.
+func (g *gormx) ReadFresh(ctx context.Context, maxLag time.Duration, fn func(db *gorm.DB) error) error {
+	if g.inTransaction() {
+		return fn(g.DB.WithContext(ctx))
+	}
+
+	return fn(g.reader(ctx, maxLag).WithContext(ctx))
+}
+
+func (g *gormx) reader(ctx context.Context, maxLag time.Duration) *gorm.DB {
+	if route, ok := ctx.Value(routeKey{}).(route); ok {
+		return g.routed(route)
+	}
+	if len(g.replicas) == 0 || g.lagChecker == nil {
+		return g.db
+	}
+
+	lag, err := g.lagChecker(ctx)
+	if err != nil || lag > maxLag {
+		return g.db
+	}
+
+	return g.replicas[0]
+}
@@ -0,0 +1,113 @@
+package gormx_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGormx_ReadFresh(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name      string
+		lag       time.Duration
+		lagErr    error
+		toReplica bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "replica within lag",
+			lag:       10 * time.Millisecond,
+			toReplica: true,
+		},
+		{
+			name:      "replica lagging",
+			lag:       time.Second,
+			toReplica: false,
+		},
+		{
+			name:      "lag check failure",
+			lagErr:    errors.New("lag unknown"),
+			toReplica: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			primary := createConnection(t)
+			replica := createConnection(t)
+			replicaSQL, _ := replica.DB()
+			defer replicaSQL.Close()
+
+			gx, _ := gormx.New(primary,
+				gormx.WithReplicas(replica),
+				gormx.WithLagChecker(func(ctx context.Context) (time.Duration, error) {
+					return tc.lag, tc.lagErr
+				}),
+			)
+			defer gx.Close()
+
+			err := gx.ReadFresh(context.Background(), 100*time.Millisecond, func(db *gorm.DB) error {
+				assert.Equal(tc.toReplica, db.Statement.ConnPool == replicaSQL)
+				return nil
+			})
+			assert.NoError(err)
+		})
+	}
+}
+
+func TestUsePrimary(t *testing.T) {
+	assert := assert.New(t)
+	primary := createConnection(t)
+	replica := createConnection(t)
+	primarySQL, _ := primary.DB()
+	replicaSQL, _ := replica.DB()
+	defer replicaSQL.Close()
+
+	gx, _ := gormx.New(primary,
+		gormx.WithReplicas(replica),
+		gormx.WithLagChecker(func(ctx context.Context) (time.Duration, error) {
+			return 0, nil
+		}),
+	)
+	defer gx.Close()
+
+	routedTo := func(ctx context.Context) *sql.DB {
+		var pool *sql.DB
+		err := gx.ReadFresh(ctx, time.Second, func(db *gorm.DB) error {
+			pool, _ = db.Statement.ConnPool.(*sql.DB)
+			return nil
+		})
+		assert.NoError(err)
+		return pool
+	}
+
+	ctx := context.Background()
+
+	// the replica is fresh enough, but the read is forced to the primary
+	assert.Same(replicaSQL, routedTo(ctx))
+	assert.Same(primarySQL, routedTo(gormx.UsePrimary(ctx)))
+
+	// the query helpers read from the primary unless routed to a replica. Raw
+	// scans through Rows, so its reads run the row callbacks
+	var replicaReads int
+	replica.Callback().Row().Before("gorm:row").Register("gormx_test:replica_reads", func(*gorm.DB) {
+		replicaReads++
+	})
+	_, err := gormx.Raw[string](gormx.UsePrimary(ctx), gx, "SELECT id FROM t1")
+	assert.NoError(err)
+	_, err = gormx.Raw[string](ctx, gx, "SELECT id FROM t1")
+	assert.NoError(err)
+	assert.Zero(replicaReads)
+	_, err = gormx.Raw[string](gormx.UseReplica(ctx), gx, "SELECT id FROM t1")
+	assert.NoError(err)
+	assert.Equal(1, replicaReads)
+}
@@ -0,0 +1,284 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	// ErrCompositeKey is returned by FindByID on models whose primary key
+	// spans several columns.
+	ErrCompositeKey = errors.New("composite primary key")
+
+	// ErrInvalidKey is returned by FindByKey when the given keys don't match
+	// the primary key of the model.
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+// Repository provides generic CRUD helpers for the model T, running on the
+// active transaction of a Gormx.
+type Repository[T any] struct {
+	gx        Gormx
+	savepoint bool
+	preloads  []preload
+}
+
+// preload is an association eager-loaded by the reads of a Repository.
+type preload struct {
+	assoc string
+	conds []any
+}
+
+// RepositoryOption configures a Repository.
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	savepoint bool
+}
+
+// WithSavepoint makes the mutating methods of the repository run in a
+// savepoint of the open transaction, if any, so that a failed operation
+// only rolls back its own changes, such as the ones made before a hook
+// fails, instead of leaving them in the caller's transaction.
+func WithSavepoint() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.savepoint = true
+	}
+}
+
+// NewRepository creates a new Repository for the model T.
+func NewRepository[T any](gx Gormx, opts ...RepositoryOption) *Repository[T] {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Repository[T]{gx: gx, savepoint: o.savepoint}
+}
+
+// db returns the active handle of the repository, bound to ctx.
+func (r *Repository[T]) db(ctx context.Context) *gorm.DB {
+	return handle(ctx, r.gx)
+}
+
+// Preload returns a copy of the repository eager-loading the association
+// assoc, with gorm's Preload and the optional conds, along with the records
+// returned by FindByID, FindByKey, List and ProcessInBatches. It saves a
+// query per record when reading related data, e.g.:
+//
+//	orders, err := repo.Preload("Items").List(ctx, "customer_id = ?", id)
+func (r *Repository[T]) Preload(assoc string, conds ...any) *Repository[T] {
+	c := *r
+	c.preloads = append(append([]preload(nil), r.preloads...), preload{assoc: assoc, conds: conds})
+	return &c
+}
+
+// query returns the active handle of the repository for a read made with
+// ctx, bounded by the timeout set by WithReadTimeout, with the associations
+// to preload applied. cancel must be called once the read is done.
+func (r *Repository[T]) query(ctx context.Context) (db *gorm.DB, cancel context.CancelFunc) {
+	db, cancel = readHandle(ctx, r.gx)
+	return r.preload(db), cancel
+}
+
+// preload applies the associations to preload to db.
+func (r *Repository[T]) preload(db *gorm.DB) *gorm.DB {
+	for _, p := range r.preloads {
+		db = db.Preload(p.assoc, p.conds...)
+	}
+	return db
+}
+
+// primaryFields returns the primary key fields of T.
+func (r *Repository[T]) primaryFields(db *gorm.DB) ([]*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema.PrimaryFields, nil
+}
+
+// FindByID returns the record of T whose single column primary key is id.
+func (r *Repository[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	db, cancel := r.query(ctx)
+	defer cancel()
+
+	fields, err := r.primaryFields(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 1 {
+		return nil, ErrCompositeKey
+	}
+
+	return r.first(db.Where(clause.Eq{
+		Column: clause.Column{Table: clause.CurrentTable, Name: fields[0].DBName},
+		Value:  id,
+	}))
+}
+
+// FindByKey returns the record of T identified by keys, which must hold a
+// value for each primary key field of T, indexed by field or column name.
+func (r *Repository[T]) FindByKey(ctx context.Context, keys map[string]any) (*T, error) {
+	db, cancel := r.query(ctx)
+	defer cancel()
+
+	fields, err := r.primaryFields(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) != len(fields) {
+		return nil, fmt.Errorf("%w: expected %d keys, got %d", ErrInvalidKey, len(fields), len(keys))
+	}
+
+	exprs := make([]clause.Expression, 0, len(fields))
+	for _, field := range fields {
+		value, ok := keys[field.DBName]
+		if !ok {
+			value, ok = keys[field.Name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidKey, field.DBName)
+		}
+
+		exprs = append(exprs, clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  value,
+		})
+	}
+
+	return r.first(db.Clauses(clause.Where{Exprs: exprs}))
+}
+
+// ExistsMany reports which of ids, values of the single column primary key
+// of T, match a record. It runs a single query selecting only the primary
+// key, and returns a map holding an entry for each of ids.
+func (r *Repository[T]) ExistsMany(ctx context.Context, ids []any) (map[any]bool, error) {
+	result := make(map[any]bool, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	db, cancel := readHandle(ctx, r.gx)
+	defer cancel()
+
+	fields, err := r.primaryFields(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != 1 {
+		return nil, ErrCompositeKey
+	}
+	field := fields[0]
+
+	column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	records := []T{}
+	if err := db.Select(column).Where(clause.IN{Column: column, Values: ids}).Find(&records).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	// the keys are compared by their printed value, as the ids may not be
+	// of the exact type of the field, e.g. int for a uint key
+	found := make(map[string]bool, len(records))
+	for i := range records {
+		value, _ := field.ValueOf(ctx, reflect.ValueOf(&records[i]).Elem())
+		found[fmt.Sprint(value)] = true
+	}
+
+	for _, id := range ids {
+		result[id] = found[fmt.Sprint(id)]
+	}
+	return result, nil
+}
+
+// List returns the records of T matching conds, as accepted by gorm's Find.
+func (r *Repository[T]) List(ctx context.Context, conds ...any) ([]T, error) {
+	result := []T{}
+	db, cancel := r.query(ctx)
+	defer cancel()
+
+	if err := db.Find(&result, conds...).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return result, nil
+}
+
+// ProcessInBatches calls fn with the records of T matching conds, as accepted
+// by gorm's Where, in batches of batchSize ordered by primary key. It stops
+// at the first error returned by fn, and returns it. The batch slice is
+// reused from one call to the next, so fn must not retain it.
+func (r *Repository[T]) ProcessInBatches(ctx context.Context, batchSize int, fn func(batch []T) error, conds ...any) error {
+	// the batches aren't bounded by the read timeout, as fn runs in between
+	db := r.preload(r.db(ctx))
+	if len(conds) > 0 {
+		db = db.Where(conds[0], conds[1:]...)
+	}
+
+	// the error of fn is returned as is, only those of gorm are translated
+	var fnErr error
+	batch := []T{}
+	err := db.FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+		fnErr = fn(batch)
+		return fnErr
+	}).Error
+	if fnErr != nil {
+		return fnErr
+	}
+	return translateError(err)
+}
+
+// Create inserts record.
+func (r *Repository[T]) Create(ctx context.Context, record *T) error {
+	return r.mutate(ctx, func(db *gorm.DB) error {
+		return db.Create(record).Error
+	})
+}
+
+// Update saves all the fields of record.
+func (r *Repository[T]) Update(ctx context.Context, record *T) error {
+	return r.mutate(ctx, func(db *gorm.DB) error {
+		return db.Save(record).Error
+	})
+}
+
+// Delete deletes record, identified by its primary key.
+func (r *Repository[T]) Delete(ctx context.Context, record *T) error {
+	return r.mutate(ctx, func(db *gorm.DB) error {
+		return db.Delete(record).Error
+	})
+}
+
+// mutate runs fn on the active handle of the repository. With WithSavepoint,
+// it runs in a savepoint of the open transaction, rolled back if fn fails.
+func (r *Repository[T]) mutate(ctx context.Context, fn func(db *gorm.DB) error) error {
+	if !r.savepoint || r.gx.Tx() == nil {
+		return translateError(fn(r.db(ctx)))
+	}
+
+	tx, err := r.gx.BeginTxxE(ctx)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return err
+	}
+
+	if err := fn(r.db(ctx)); err != nil {
+		tx.Rollbackx()
+		return translateError(err)
+	}
+	return tx.Commitx()
+}
+
+func (r *Repository[T]) first(db *gorm.DB) (*T, error) {
+	record := new(T)
+	if err := db.First(record).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return record, nil
+}
@@ -0,0 +1,234 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRepository_FindByID(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T1](gx)
+
+	assert.NoError(repo.Create(ctx, &models.T1{ID: "abc"}))
+
+	t1, err := repo.FindByID(ctx, "abc")
+	assert.NoError(err)
+	assert.Equal(&models.T1{ID: "abc"}, t1)
+
+	_, err = repo.FindByID(ctx, "missing")
+	assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+	_, err = gormx.NewRepository[models.T4](gx).FindByID(ctx, "abc")
+	assert.ErrorIs(err, gormx.ErrCompositeKey)
+}
+
+func TestRepository_FindByKey(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T4](gx)
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+
+	assert.NoError(repo.Create(ctx, &models.T4{OrgID: "org1", ID: "abc", Name: "first"}))
+	assert.NoError(repo.Create(ctx, &models.T4{OrgID: "org2", ID: "abc", Name: "second"}))
+
+	t4, err := repo.FindByKey(ctx, map[string]any{"org_id": "org2", "ID": "abc"})
+	assert.NoError(err)
+	assert.Equal("second", t4.Name)
+
+	_, err = repo.FindByKey(ctx, map[string]any{"org_id": "org2"})
+	assert.ErrorIs(err, gormx.ErrInvalidKey)
+
+	_, err = repo.FindByKey(ctx, map[string]any{"org_id": "org2", "name": "second"})
+	assert.ErrorIs(err, gormx.ErrInvalidKey)
+}
+
+func TestRepository_Create_AutoIncrement(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T5](gx)
+
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+
+	first := &models.T5{Name: "first"}
+	assert.NoError(repo.Create(ctx, first))
+	second := &models.T5{Name: "second"}
+	assert.NoError(repo.Create(ctx, second))
+
+	assert.NoError(gx.Commitx())
+	assert.NoError(gx.Commitx())
+
+	assert.NotZero(first.ID)
+	assert.Greater(second.ID, first.ID)
+
+	t5, err := repo.FindByID(ctx, second.ID)
+	assert.NoError(err)
+	assert.Equal("second", t5.Name)
+}
+
+func TestRepository_ExistsMany(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T1](gx)
+
+	gx.BeginTxx(ctx)
+	defer gx.Rollbackx()
+
+	assert.NoError(repo.Create(ctx, &models.T1{ID: "abc"}))
+	assert.NoError(repo.Create(ctx, &models.T1{ID: "def"}))
+
+	exists, err := repo.ExistsMany(ctx, []any{"abc", "missing", "def"})
+	assert.NoError(err)
+	assert.Equal(map[any]bool{"abc": true, "missing": false, "def": true}, exists)
+
+	exists, err = repo.ExistsMany(ctx, nil)
+	assert.NoError(err)
+	assert.Empty(exists)
+
+	_, err = gormx.NewRepository[models.T4](gx).ExistsMany(ctx, []any{"abc"})
+	assert.ErrorIs(err, gormx.ErrCompositeKey)
+}
+
+func TestRepository_ProcessInBatches(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T5](gx)
+
+	records := make([]models.T5, 2500)
+	for i := range records {
+		records[i].Name = "row"
+	}
+	assert.NoError(db.CreateInBatches(records, 500).Error)
+
+	gx.BeginTxx(ctx)
+	defer gx.Rollbackx()
+
+	batches, processed := 0, 0
+	err := repo.ProcessInBatches(ctx, 500, func(batch []models.T5) error {
+		batches++
+		processed += len(batch)
+		return nil
+	}, "name = ?", "row")
+	assert.NoError(err)
+	assert.Equal(5, batches)
+	assert.Equal(2500, processed)
+
+	// errors stop the processing
+	failure := errors.New("failure")
+	batches = 0
+	err = repo.ProcessInBatches(ctx, 500, func(batch []models.T5) error {
+		batches++
+		return failure
+	})
+	assert.ErrorIs(err, failure)
+	assert.Equal(1, batches)
+
+	// the errors of fn aren't translated
+	err = repo.ProcessInBatches(ctx, 500, func(batch []models.T5) error {
+		return gorm.ErrRecordNotFound
+	})
+	assert.Equal(gorm.ErrRecordNotFound, err)
+}
+
+// failingT6 fails its AfterUpdate hook, once the update has run, when
+// renamed "fail".
+type failingT6 struct {
+	models.T6
+}
+
+func (failingT6) TableName() string {
+	return "t6"
+}
+
+func (f *failingT6) AfterUpdate(tx *gorm.DB) error {
+	if f.Name == "fail" {
+		return errors.New("hook failure")
+	}
+	return nil
+}
+
+func TestRepository_WithSavepoint(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[failingT6](gx, gormx.WithSavepoint())
+
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(repo.Create(ctx, &failingT6{T6: models.T6{ID: "abc", Name: "before"}}))
+
+	err := repo.Update(ctx, &failingT6{T6: models.T6{ID: "abc", Name: "fail"}})
+	assert.Error(err)
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	t6, err := repo.FindByID(ctx, "abc")
+	assert.NoError(err)
+	assert.Equal("before", t6.Name)
+}
+
+func TestRepository_Preload(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	repo := gormx.NewRepository[models.T7](gx)
+
+	assert.NoError(repo.Create(ctx, &models.T7{ID: "a", Name: "first", T8: models.T8{ID: "a8", Name: "eight"}}))
+	assert.NoError(repo.Create(ctx, &models.T7{ID: "b", Name: "second", T8: models.T8{ID: "b8", Name: "other"}}))
+
+	tx := gx.BeginTxx(ctx)
+	defer tx.Rollbackx()
+
+	t7, err := repo.FindByID(ctx, "a")
+	assert.NoError(err)
+	assert.Empty(t7.T8.ID)
+
+	t7, err = repo.Preload("T8").FindByID(ctx, "a")
+	assert.NoError(err)
+	assert.Equal(models.T8{ID: "a8", T7ID: "a", Name: "eight"}, t7.T8)
+
+	t7s, err := repo.Preload("T8", "name = ?", "other").List(ctx)
+	assert.NoError(err)
+	if assert.Len(t7s, 2) {
+		assert.Empty(t7s[0].T8.ID)
+		assert.Equal("b8", t7s[1].T8.ID)
+	}
+}
@@ -0,0 +1,126 @@
+package gormx
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RequestIDTag is the TxStats.Tags key under which the request ID of a
+// transaction is passed to the commit and rollback hooks.
+const RequestIDTag = "request_id"
+
+// WithRequestIDKey makes each top-level transaction read a request ID from
+// the value its context holds under key, if any, e.g. the one set by an HTTP
+// middleware. The request ID then prefixes the statements of the transaction
+// logged by gorm, is passed to the hooks in TxStats.Tags under RequestIDTag,
+// to label metrics, and is reported by ActiveSavepoints. WithRequestIDComment
+// also adds it to the statements themselves.
+func WithRequestIDKey(key any) Option {
+	return func(g *gormx) error {
+		g.requestIDKey = key
+		return nil
+	}
+}
+
+// WithRequestIDComment makes the statements of the transactions annotated by
+// WithRequestIDKey start with a sqlcommenter comment carrying their request
+// ID, such as /*request_id='req-1'*/, so that it shows in the database's
+// process list and slow query log. The request ID is URL encoded, as
+// sqlcommenter requires.
+func WithRequestIDComment() Option {
+	return func(g *gormx) error {
+		g.requestIDComment = true
+		return nil
+	}
+}
+
+// annotateRequestID reads the request ID of the transaction just begun from
+// ctx and annotates its logs and stats with it.
+func (g *gormx) annotateRequestID(ctx context.Context) {
+	g.requestID = ""
+	if g.requestIDKey == nil {
+		return
+	}
+
+	v := ctx.Value(g.requestIDKey)
+	if v == nil {
+		return
+	}
+	g.requestID = fmt.Sprint(v)
+
+	// the tags returned by WithTagsFromContext may be shared
+	tags := make(map[string]string, len(g.stats.Tags)+1)
+	for k, v := range g.stats.Tags {
+		tags[k] = v
+	}
+	tags[RequestIDTag] = g.requestID
+	g.stats.Tags = tags
+
+	g.DB = g.DB.Session(&gorm.Session{Logger: namedLogger{Interface: g.DB.Logger, name: RequestIDTag + "=" + g.requestID}})
+}
+
+// registerRequestIDCallbacks installs the callbacks of WithRequestIDComment
+// on db. Raw statements are prefixed with the comment, while the others get
+// it before their main clause, being built by gorm's own callback.
+func registerRequestIDCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().Before("gorm:create").Register("gormx:request_id", commentRequestID("INSERT")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("gormx:request_id", commentRequestID("SELECT")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("gormx:request_id", commentRequestID("UPDATE")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("gormx:request_id", commentRequestID("DELETE")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("gormx:request_id", commentRequestID("SELECT")); err != nil {
+		return err
+	}
+	return cb.Raw().Before("gorm:raw").Register("gormx:request_id", commentRequestID(""))
+}
+
+// commentRequestID returns the callback adding the request ID comment to the
+// statements built around clauseName.
+func commentRequestID(clauseName string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil {
+			return
+		}
+
+		g, ok := fromStatement(db)
+		if !ok || !g.requestIDComment || g.requestID == "" || g.internal || !g.inTransaction() {
+			return
+		}
+
+		comment := requestIDComment(g.requestID)
+		stmt := db.Statement
+		if stmt.SQL.Len() > 0 || clauseName == "" {
+			sql := comment.SQL + " " + stmt.SQL.String()
+			stmt.SQL.Reset()
+			stmt.SQL.WriteString(sql)
+			return
+		}
+
+		c := stmt.Clauses[clauseName]
+		if c.BeforeExpression == nil {
+			c.BeforeExpression = comment
+		} else {
+			c.BeforeExpression = clause.Expr{SQL: comment.SQL + " ?", Vars: []any{c.BeforeExpression}}
+		}
+		stmt.Clauses[clauseName] = c
+	}
+}
+
+// requestIDComment returns the sqlcommenter comment carrying requestID.
+func requestIDComment(requestID string) clause.Expr {
+	value := strings.ReplaceAll(url.QueryEscape(requestID), "+", "%20")
+	return clause.Expr{SQL: "/*" + RequestIDTag + "='" + value + "'*/"}
+}
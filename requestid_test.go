@@ -0,0 +1,86 @@
+package gormx_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type requestIDKey struct{}
+
+func TestWithRequestIDKey(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	var tags []map[string]string
+	gx, _ := gormx.New(db, gormx.WithRequestIDKey(requestIDKey{}), gormx.OnCommit(func(stats gormx.TxStats) {
+		tags = append(tags, stats.Tags)
+	}))
+	defer gx.Close()
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+
+	savepoints := gx.ActiveSavepoints()
+	if assert.Len(savepoints, 2) {
+		assert.Equal("req-1", savepoints[1].RequestID)
+	}
+
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+
+	assert.Contains(rec.Statements(), "[request_id=req-1] INSERT INTO t1(id) VALUES('abc')")
+	assert.Equal([]map[string]string{{gormx.RequestIDTag: "req-1"}}, tags)
+
+	// transactions begun without a request ID aren't annotated
+	tx = gx.BeginTxx(context.Background())
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('def')").Error)
+	assert.NoError(tx.Commitx())
+
+	assert.Contains(rec.Statements(), "INSERT INTO t1(id) VALUES('def')")
+	if assert.Len(tags, 2) {
+		assert.Empty(tags[1])
+	}
+}
+
+func TestWithRequestIDComment(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	gx, _ := gormx.New(db, gormx.WithRequestIDKey(requestIDKey{}), gormx.WithRequestIDComment())
+	defer gx.Close()
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	tx := gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(tx.Create(&T2{ID: "abc"}).Error)
+
+	var t1s []T1
+	assert.NoError(tx.Find(&t1s).Error)
+	assert.NoError(tx.Commitx())
+
+	statements := strings.Join(rec.Statements(), "\n")
+	assert.Contains(statements, "/*request_id='req-1'*/ INSERT INTO t1(id) VALUES('abc')")
+	assert.Contains(statements, "/*request_id='req-1'*/ INSERT INTO `t2`")
+	assert.Contains(statements, "/*request_id='req-1'*/ SELECT * FROM `t1`")
+
+	// the request ID can't end the comment
+	ctx = context.WithValue(context.Background(), requestIDKey{}, "req 2*/")
+	tx = gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('def')").Error)
+	assert.NoError(tx.Commitx())
+
+	assert.Contains(rec.Statements(), "[request_id=req 2*/] /*request_id='req%202%2A%2F'*/ INSERT INTO t1(id) VALUES('def')")
+
+	// nor are statements outside of a transaction commented
+	assert.NoError(gx.Gorm().Find(&t1s).Error)
+	assert.NotContains(rec.Statements()[len(rec.Statements())-1], "request_id")
+}
@@ -0,0 +1,136 @@
+package gormx
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// CreateReturning inserts record using the active transaction of gx and
+// populates it from a RETURNING clause, so that values generated by the
+// database such as defaults and sequences are read back without a separate
+// query. It returns the given columns, or all of them if none are given.
+//
+// RETURNING is supported by Postgres and SQLite, but not by MySQL, where gorm
+// populates auto-increment primary keys from LastInsertId on Create instead.
+func CreateReturning[T any](ctx context.Context, gx Gormx, record *T, columns ...string) error {
+	returning := clause.Returning{}
+	for _, column := range columns {
+		returning.Columns = append(returning.Columns, clause.Column{Name: column})
+	}
+
+	return translateError(handle(ctx, gx).Clauses(returning).Create(record).Error)
+}
+
+// DeleteReturning deletes the records of T matching conds, as accepted by
+// gorm's Delete, using the active transaction of gx, and returns them. On
+// Postgres and SQLite they are read back from a RETURNING clause. Elsewhere,
+// such as on MySQL, they are selected and locked first, then deleted by
+// primary key, in a nested transaction for atomicity.
+//
+// Like gorm's Delete, it refuses to delete without conditions.
+func DeleteReturning[T any](ctx context.Context, gx Gormx, conds ...any) ([]T, error) {
+	if len(conds) == 0 {
+		return nil, gorm.ErrMissingWhereClause
+	}
+
+	deleted := []T{}
+
+	switch gx.Gorm().Dialector.Name() {
+	case "postgres", "sqlite":
+		err := handle(ctx, gx).Clauses(clause.Returning{}).Delete(&deleted, conds...).Error
+		return deleted, translateError(err)
+	}
+
+	tx, err := gx.BeginTxxE(ctx)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return nil, err
+	}
+
+	db := handle(ctx, tx)
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where(conds[0], conds[1:]...).Find(&deleted).Error; err != nil {
+		tx.Rollbackx()
+		return nil, translateError(err)
+	}
+	if len(deleted) > 0 {
+		if err := db.Delete(&deleted).Error; err != nil {
+			tx.Rollbackx()
+			return nil, translateError(err)
+		}
+	}
+
+	return deleted, tx.Commitx()
+}
+
+// UpdateReturning updates the records of T matching conds, as accepted by
+// gorm's Where, with values, as accepted by gorm's Updates, using the active
+// transaction of gx, and returns them as updated. On Postgres and SQLite they
+// are read back from a RETURNING clause. Elsewhere, such as on MySQL, the
+// matching records are locked first, then updated and selected again by
+// primary key, in a nested transaction: no other transaction can change them
+// in between, and records matching conds only after the update aren't
+// returned.
+//
+// Like gorm's Updates, it refuses to update without conditions.
+func UpdateReturning[T any](ctx context.Context, gx Gormx, values any, conds ...any) ([]T, error) {
+	if len(conds) == 0 {
+		return nil, gorm.ErrMissingWhereClause
+	}
+
+	updated := []T{}
+
+	switch gx.Gorm().Dialector.Name() {
+	case "postgres", "sqlite":
+		err := handle(ctx, gx).Model(&updated).Clauses(clause.Returning{}).Where(conds[0], conds[1:]...).Updates(values).Error
+		return updated, translateError(err)
+	}
+
+	tx, err := gx.BeginTxxE(ctx)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return nil, err
+	}
+
+	db := handle(ctx, tx)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		tx.Rollbackx()
+		return nil, err
+	}
+
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Where(conds[0], conds[1:]...).Find(&updated).Error; err != nil {
+		tx.Rollbackx()
+		return nil, translateError(err)
+	}
+	if len(updated) == 0 {
+		return updated, tx.Commitx()
+	}
+
+	// the records are identified by primary key, as the update may change
+	// whether they match conds
+	_, keys := schema.GetIdentityFieldValuesMap(ctx, reflect.ValueOf(updated), stmt.Schema.PrimaryFields)
+	column, keyValues := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, keys)
+	byKey := clause.IN{Column: column, Values: keyValues}
+
+	if err := db.Model(new(T)).Where(byKey).Updates(values).Error; err != nil {
+		tx.Rollbackx()
+		return nil, translateError(err)
+	}
+
+	updated = []T{}
+	if err := db.Where(byKey).Find(&updated).Error; err != nil {
+		tx.Rollbackx()
+		return nil, translateError(err)
+	}
+
+	return updated, tx.Commitx()
+}
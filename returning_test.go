@@ -0,0 +1,107 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateReturning(t *testing.T) {
+	assert := assert.New(t)
+	db := createPostgresConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+
+	record := &models.T5{Name: "abc"}
+	assert.NoError(gormx.CreateReturning(ctx, tx, record))
+	assert.Equal(uint(1), record.ID)
+
+	record = &models.T5{Name: "def"}
+	assert.NoError(gormx.CreateReturning(ctx, tx, record, "id"))
+	assert.Equal(uint(2), record.ID)
+
+	assert.NoError(tx.Commitx())
+}
+
+func TestDeleteReturning(t *testing.T) {
+	connections := map[string]func(testing.TB) *gorm.DB{
+		"mysql":    createConnection,
+		"postgres": createPostgresConnection,
+	}
+
+	for name, connect := range connections {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			db := connect(t)
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			db.Create(&[]models.T5{{Name: "keep"}, {Name: "drop"}, {Name: "drop"}})
+
+			tx := gx.BeginTxx(ctx)
+			deleted, err := gormx.DeleteReturning[models.T5](ctx, tx, "name = ?", "drop")
+			assert.NoError(err)
+			assert.NoError(tx.Commitx())
+
+			assert.ElementsMatch([]models.T5{{ID: 2, Name: "drop"}, {ID: 3, Name: "drop"}}, deleted)
+
+			var remaining []models.T5
+			gx.Gorm().Find(&remaining)
+			assert.Equal([]models.T5{{ID: 1, Name: "keep"}}, remaining)
+
+			_, err = gormx.DeleteReturning[models.T5](ctx, gx)
+			assert.ErrorIs(err, gorm.ErrMissingWhereClause)
+		})
+	}
+}
+
+func TestUpdateReturning(t *testing.T) {
+	connections := map[string]func(testing.TB) *gorm.DB{
+		"mysql":    createConnection,
+		"postgres": createPostgresConnection,
+	}
+
+	for name, connect := range connections {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			db := connect(t)
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			db.Create(&[]models.T5{{Name: "keep"}, {Name: "pending"}, {Name: "pending"}})
+
+			tx := gx.BeginTxx(ctx)
+			updated, err := gormx.UpdateReturning[models.T5](ctx, tx, map[string]any{"name": "done"}, "name = ?", "pending")
+			assert.NoError(err)
+			assert.NoError(tx.Commitx())
+
+			assert.ElementsMatch([]models.T5{{ID: 2, Name: "done"}, {ID: 3, Name: "done"}}, updated)
+
+			var t5s []models.T5
+			gx.Gorm().Order("id").Find(&t5s)
+			assert.Equal([]models.T5{{ID: 1, Name: "keep"}, {ID: 2, Name: "done"}, {ID: 3, Name: "done"}}, t5s)
+
+			updated, err = gormx.UpdateReturning[models.T5](ctx, gx, map[string]any{"name": "done"}, "name = ?", "missing")
+			assert.NoError(err)
+			assert.Empty(updated)
+
+			_, err = gormx.UpdateReturning[models.T5](ctx, gx, map[string]any{"name": "done"})
+			assert.ErrorIs(err, gorm.ErrMissingWhereClause)
+
+			_, err = gormx.UpdateReturning[models.T5](ctx, gx, map[string]any{"id": 1}, "id = ?", 2)
+			assert.ErrorIs(err, gormx.ErrDuplicateKey)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package gormx
+
+import (
+	"context"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultUpsertThreshold = 100
+	defaultUpsertBatchSize = 500
+)
+
+// SmartSaveOptions tunes the strategy used by SmartSave.
+type SmartSaveOptions struct {
+	// UpsertThreshold is the number of records from which SmartSave switches
+	// from individual saves to batched upserts. Defaults to 100.
+	UpsertThreshold int
+	// BatchSize is the number of records per upsert statement.
+	// Defaults to 500.
+	BatchSize int
+}
+
+// SmartSave saves records using the active transaction of gx.
+//
+// Slices smaller than opts.UpsertThreshold are saved one record at a time,
+// which lets gorm run its usual update-or-create logic and hooks per record.
+// Larger slices are written as batched upserts (INSERT ... ON DUPLICATE KEY
+// UPDATE on MySQL, ON CONFLICT on Postgres) of opts.BatchSize records each,
+// trading per-record semantics for far fewer round trips.
+func SmartSave[T any](ctx context.Context, gx Gormx, records []T, opts SmartSaveOptions) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	if opts.UpsertThreshold <= 0 {
+		opts.UpsertThreshold = defaultUpsertThreshold
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultUpsertBatchSize
+	}
+
+	db := handle(ctx, gx)
+
+	if len(records) >= opts.UpsertThreshold {
+		return translateError(db.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(records, opts.BatchSize).Error)
+	}
+
+	for i := range records {
+		if err := db.Save(&records[i]).Error; err != nil {
+			return translateError(err)
+		}
+	}
+
+	return nil
+}
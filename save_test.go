@@ -0,0 +1,41 @@
+package gormx_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmartSave(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+
+	err := gormx.SmartSave(ctx, gx, []models.T1{{ID: "single"}}, gormx.SmartSaveOptions{})
+	assert.NoError(err)
+
+	var many []models.T2
+	for i := 0; i < 2000; i++ {
+		many = append(many, models.T2{ID: strconv.Itoa(i)})
+	}
+	err = gormx.SmartSave(ctx, gx, many, gormx.SmartSaveOptions{})
+	assert.NoError(err)
+
+	tx.Commitx()
+
+	var t1Count, t2Count int64
+	gx.Gorm().Model(&models.T1{}).Count(&t1Count)
+	gx.Gorm().Model(&models.T2{}).Count(&t2Count)
+
+	assert.Equal(int64(1), t1Count)
+	assert.Equal(int64(2000), t2Count)
+}
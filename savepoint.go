@@ -0,0 +1,362 @@
+package gormx
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSavePointPrefix = "sp_"
+
+	// defaultMaxSavepointNameLength is MySQL's identifier length limit.
+	defaultMaxSavepointNameLength = 64
+
+	// minSavePointRandomLength is the number of random characters kept in
+	// a savepoint name when it has to be shortened.
+	minSavePointRandomLength = 8
+
+	// joinedSavePointID stands for the savepoint of a nested transaction
+	// joining the current one with PropagationRequired, which has none. It
+	// can't collide with a generated name, which is never empty.
+	joinedSavePointID = "\x00joined"
+)
+
+var (
+	// ErrInvalidSavepointName is returned when the savepoint name options
+	// can't produce valid savepoint names.
+	ErrInvalidSavepointName = errors.New("invalid savepoint name")
+
+	// ErrInvalidDepth is returned by ExecIn for a nesting depth that isn't
+	// open, and by WithExpectedDepth for a depth lower than 1.
+	ErrInvalidDepth = errors.New("invalid transaction depth")
+)
+
+// WithSavepointPrefix sets the prefix of generated savepoint names.
+// It defaults to "sp_".
+func WithSavepointPrefix(prefix string) Option {
+	return func(g *gormx) error {
+		if prefix == "" {
+			return ErrInvalidSavepointName
+		}
+		g.savePointPrefix = prefix
+		return nil
+	}
+}
+
+// WithMaxSavepointNameLength sets the maximum length of generated savepoint
+// names. It defaults to 64, MySQL's identifier length limit.
+func WithMaxSavepointNameLength(n int) Option {
+	return func(g *gormx) error {
+		if n < minSavePointRandomLength+1 {
+			return ErrInvalidSavepointName
+		}
+		g.maxSavePointNameLength = n
+		return nil
+	}
+}
+
+// WithSavepointCheck makes New verify that the dialector supports savepoints,
+// or that WithSavepointSQL is set, and return ErrIncompatibleOption otherwise,
+// so that a DB unable to nest transactions is rejected upfront rather than
+// when the first nested transaction begins.
+func WithSavepointCheck() Option {
+	return func(g *gormx) error {
+		g.checkSavePoints = true
+		return nil
+	}
+}
+
+// supportsSavePoints reports whether savepoints can be created on g.
+func (g *gormx) supportsSavePoints() bool {
+	if g.savePointSQL != nil {
+		return true
+	}
+	_, ok := g.db.Dialector.(gorm.SavePointerDialectorInterface)
+	return ok
+}
+
+// savePointNameBuffers holds the buffers savepoint names are built in, to
+// save an allocation per name under high transaction throughput.
+var savePointNameBuffers = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, defaultMaxSavepointNameLength)
+		return &b
+	},
+}
+
+// WithExpectedDepth pre-sizes the savepoint stack for n nested transactions,
+// to save reallocations when growing it under high transaction throughput.
+func WithExpectedDepth(n int) Option {
+	return func(g *gormx) error {
+		if n < 1 {
+			return ErrInvalidDepth
+		}
+		g.expectedDepth = n
+		return nil
+	}
+}
+
+// newSavePointID generates a savepoint name unique within the current stack.
+func (g *gormx) newSavePointID() string {
+	buf := savePointNameBuffers.Get().(*[]byte)
+	defer savePointNameBuffers.Put(buf)
+
+	for {
+		var random [36]byte
+		*buf = appendSavePointName((*buf)[:0], g.savePointPrefix, hex128(random[:0], uuids.Next()), g.maxSavePointNameLength)
+		id := string(*buf)
+		if !g.hasSavePoint(id) {
+			return id
+		}
+	}
+}
+
+// savePointSQL holds the functions generating the savepoint statements set
+// by WithSavepointSQL.
+type savePointSQL struct {
+	begin    func(name string) string
+	rollback func(name string) string
+	release  func(name string) string
+}
+
+// WithSavepointSQL overrides the statements creating, rolling back to and
+// releasing savepoints, for setups whose syntax differs from the dialect's,
+// such as proxies. Each function is given the unquoted savepoint name and
+// returns the statement to run.
+//
+// release may be nil, in which case savepoints are kept until the top-level
+// transaction resolves, as by default. Otherwise it is run when a nested
+// transaction commits.
+func WithSavepointSQL(begin, rollback, release func(name string) string) Option {
+	return func(g *gormx) error {
+		if begin == nil || rollback == nil {
+			return ErrIncompatibleOption
+		}
+		g.savePointSQL = &savePointSQL{
+			begin:    begin,
+			rollback: rollback,
+			release:  release,
+		}
+		return nil
+	}
+}
+
+// savePoint creates the savepoint name in the transaction, adding any error
+// to it. The name is quoted with the dialect's quoter, so that any prefix is
+// safe, on the dialects known to use the standard savepoint syntax.
+func (g *gormx) savePoint(name string) *gorm.DB {
+	if g.savePointSQL != nil {
+		return g.exec(g.savePointSQL.begin(name))
+	}
+	if _, ok := g.DB.Dialector.(gorm.SavePointerDialectorInterface); !ok || !standardSavePoints(g.dialect()) {
+		return g.SavePoint(name)
+	}
+
+	return g.exec("SAVEPOINT " + g.DB.Statement.Quote(name))
+}
+
+// rollbackTo rolls the transaction back to the savepoint name, adding any
+// error to it. The name is quoted like in savePoint.
+func (g *gormx) rollbackTo(name string) *gorm.DB {
+	if g.savePointSQL != nil {
+		return g.exec(g.savePointSQL.rollback(name))
+	}
+	if _, ok := g.DB.Dialector.(gorm.SavePointerDialectorInterface); !ok || !standardSavePoints(g.dialect()) {
+		return g.RollbackTo(name)
+	}
+
+	return g.exec("ROLLBACK TO SAVEPOINT " + g.DB.Statement.Quote(name))
+}
+
+// exec runs the savepoint statement sql in the transaction, adding any error
+// to it. A transaction that already failed is left as is: gorm wouldn't run
+// the statement, and would only return the same error again.
+func (g *gormx) exec(sql string) *gorm.DB {
+	if g.DB.Error == nil {
+		g.DB.AddError(g.DB.Exec(sql).Error)
+	}
+	return g.DB
+}
+
+// release releases the savepoint name of a committed nested transaction,
+// when WithSavepointSQL sets a release statement.
+func (g *gormx) release(name string) error {
+	if g.savePointSQL == nil || g.savePointSQL.release == nil {
+		return nil
+	}
+
+	g.internal = true
+	defer func() { g.internal = false }()
+	return g.DB.Exec(g.savePointSQL.release(name)).Error
+}
+
+// standardSavePoints reports whether dialect uses the standard SAVEPOINT,
+// ROLLBACK TO SAVEPOINT and RELEASE SAVEPOINT statements.
+func standardSavePoints(dialect string) bool {
+	switch dialect {
+	case "mysql", "postgres", "sqlite":
+		return true
+	}
+	return false
+}
+
+// pushSavePoint records a savepoint created for a nested transaction.
+func (g *gormx) pushSavePoint(id string) {
+	g.savePointIDs = append(g.savePointIDs, id)
+	g.savePointTimes = append(g.savePointTimes, time.Now())
+}
+
+// savePointName returns the name of the savepoint id, empty for a joined
+// transaction.
+func savePointName(id string) string {
+	if id == joinedSavePointID {
+		return ""
+	}
+	return id
+}
+
+// popSavePoint forgets the savepoint of the innermost transaction.
+func (g *gormx) popSavePoint() {
+	g.savePointIDs = g.savePointIDs[:len(g.savePointIDs)-1]
+	g.savePointTimes = g.savePointTimes[:len(g.savePointTimes)-1]
+}
+
+// resolveFailedBegin resolves the nested transaction of a BeginTxx that
+// failed to create its savepoint: it is popped and, unless another such
+// transaction remains open, the error failing the transaction is cleared for
+// the outer one to remain usable. It returns the error of the failed begin.
+func (g *gormx) resolveFailedBegin() error {
+	err := g.DB.Error
+	g.popSavePoint()
+	for _, id := range g.savePointIDs {
+		if id == "" {
+			return err
+		}
+	}
+	g.DB.Error = nil
+	return err
+}
+
+// resetSavePoints empties the savepoint stack, keeping its storage for the
+// next transaction.
+func (g *gormx) resetSavePoints() {
+	if cap(g.savePointIDs) < g.expectedDepth {
+		g.savePointIDs = make([]string, 0, g.expectedDepth)
+		g.savePointTimes = make([]time.Time, 0, g.expectedDepth)
+		return
+	}
+	g.savePointIDs = g.savePointIDs[:0]
+	g.savePointTimes = g.savePointTimes[:0]
+}
+
+func (g *gormx) hasSavePoint(id string) bool {
+	for _, existing := range g.savePointIDs {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// appendSavePointName appends prefix and random to dst, truncating them to
+// fit within max characters. The prefix is shortened first, as long as it
+// leaves room for at least minSavePointRandomLength random characters.
+func appendSavePointName(dst []byte, prefix string, random []byte, max int) []byte {
+	if len(prefix)+len(random) > max {
+		if room := max - minSavePointRandomLength; len(prefix) > room {
+			prefix = prefix[:room]
+		}
+		random = random[:max-len(prefix)]
+	}
+
+	dst = append(dst, prefix...)
+	return append(dst, random...)
+}
+
+// appendHex appends the hexadecimal encoding of src to dst.
+func appendHex(dst, src []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, hex.EncodedLen(len(src)))...)
+	hex.Encode(dst[n:], src)
+	return dst
+}
+
+// hex128 appends uuid to dst formatted like fastuuid.Hex128, with
+// underscores in place of dashes as savepoint names cannot contain them.
+func hex128(dst []byte, uuid [24]byte) []byte {
+	uuid[6], uuid[9] = uuid[9], uuid[6]
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	dst = appendHex(dst, uuid[0:4])
+	dst = append(dst, '_')
+	dst = appendHex(dst, uuid[4:6])
+	dst = append(dst, '_')
+	dst = appendHex(dst, uuid[6:8])
+	dst = append(dst, '_')
+	dst = appendHex(dst, uuid[8:10])
+	dst = append(dst, '_')
+	return appendHex(dst, uuid[10:16])
+}
+
+// SavepointInfo describes the savepoint of an open nested transaction.
+type SavepointInfo struct {
+	// ID is the name of the savepoint, empty for a nested transaction begun
+	// by a BeginTxx that failed to create it or joining the current one with
+	// PropagationRequired.
+	ID string
+	// Depth is the nesting depth of the transaction, 1 being the top-level
+	// transaction.
+	Depth     int
+	CreatedAt time.Time
+	// RequestID is the request ID of the transaction, with WithRequestIDKey.
+	RequestID string
+}
+
+// ActiveSavepoints returns the savepoints of the open nested transactions,
+// outermost first, e.g. to monitor long-running transactions. It returns nil
+// outside of a transaction.
+func (g *gormx) ActiveSavepoints() []SavepointInfo {
+	if !g.inTransaction() {
+		return nil
+	}
+
+	infos := make([]SavepointInfo, len(g.savePointIDs))
+	for i, id := range g.savePointIDs {
+		infos[i] = SavepointInfo{
+			ID:        savePointName(id),
+			Depth:     i + 1,
+			CreatedAt: g.savePointTimes[i],
+			RequestID: g.requestID,
+		}
+	}
+	return infos
+}
+
+// ExecIn returns the transaction handle for the given nesting depth, 1 being
+// the top-level transaction, e.g. to run statements on behalf of an outer
+// level from code that only knows its depth. The returned handle carries
+// ErrInvalidDepth if that depth isn't open.
+//
+// All depths share the same database transaction, and SQL can't attach a
+// statement to an outer savepoint: statements run through the handle are
+// still undone by rolling back any savepoint opened before them, including
+// those of the deeper levels open when they run.
+func (g *gormx) ExecIn(savepointDepth int) *gorm.DB {
+	depth := 0
+	if g.inTransaction() {
+		depth = g.transactionCount - g.commitCount
+	}
+
+	if savepointDepth < 1 || savepointDepth > depth {
+		db := g.db.Session(&gorm.Session{NewDB: true})
+		db.AddError(fmt.Errorf("%w: %d", ErrInvalidDepth, savepointDepth))
+		return db
+	}
+	return g.DB
+}
@@ -0,0 +1,325 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWithMaxSavepointNameLength(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	prefix := "a_very_long_savepoint_prefix_that_would_not_fit_"
+	gx, err := gormx.New(db,
+		gormx.WithSavepointPrefix(prefix),
+		gormx.WithMaxSavepointNameLength(56),
+	)
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	assert.NoError(tx.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(gx.Commitx())
+	assert.NoError(gx.Commitx())
+
+	var names []string
+	for _, stmt := range rec.Statements() {
+		if name := strings.TrimPrefix(stmt, "SAVEPOINT "); name != stmt {
+			names = append(names, name)
+		}
+	}
+
+	assert.Len(names, 2)
+	assert.NotEqual(names[0], names[1])
+	for _, name := range names {
+		assert.LessOrEqual(len(name), 56)
+		assert.True(strings.HasPrefix(name, prefix[:40]))
+	}
+}
+
+func TestWithMaxSavepointNameLength_Invalid(t *testing.T) {
+	db := createConnection(t)
+	sql, _ := db.DB()
+	defer sql.Close()
+
+	_, err := gormx.New(db, gormx.WithMaxSavepointNameLength(4))
+	assert.ErrorIs(t, err, gormx.ErrInvalidSavepointName)
+}
+
+func TestGormx_ActiveSavepoints(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.Nil(gx.ActiveSavepoints())
+
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	gx.BeginTxx(ctx)
+	gx.Commitx()
+
+	savepoints := gx.ActiveSavepoints()
+	if assert.Len(savepoints, 2) {
+		for i, sp := range savepoints {
+			assert.Equal(i+1, sp.Depth)
+			assert.NotEmpty(sp.ID)
+			assert.False(sp.CreatedAt.IsZero())
+		}
+		assert.False(savepoints[1].CreatedAt.Before(savepoints[0].CreatedAt))
+	}
+
+	gx.Rollbackx()
+	assert.Len(gx.ActiveSavepoints(), 1)
+
+	gx.Commitx()
+	assert.Nil(gx.ActiveSavepoints())
+}
+
+func TestWithExpectedDepth(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	gx, err := gormx.New(db, gormx.WithExpectedDepth(2))
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	// nest deeper than expected, twice, to go through the reused stack
+	for i := 0; i < 2; i++ {
+		gx.BeginTxx(ctx)
+		gx.BeginTxx(ctx)
+		gx.BeginTxx(ctx)
+		assert.Len(gx.ActiveSavepoints(), 3)
+		assert.NoError(gx.Commitx())
+		assert.NoError(gx.Commitx())
+		assert.NoError(gx.Commitx())
+	}
+
+	name := regexp.MustCompile(`^SAVEPOINT sp_[0-9a-f]{8}_[0-9a-f]{4}_4[0-9a-f]{3}_[89ab][0-9a-f]{3}_[0-9a-f]{12}$`)
+	count := 0
+	for _, stmt := range rec.Statements() {
+		if strings.HasPrefix(stmt, "SAVEPOINT ") {
+			assert.Regexp(name, stmt)
+			count++
+		}
+	}
+	assert.Equal(6, count)
+
+	_, err = gormx.New(db, gormx.WithExpectedDepth(0))
+	assert.ErrorIs(err, gormx.ErrInvalidDepth)
+}
+
+func BenchmarkNestedTransactions(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		options []gormx.Option
+	}{
+		{name: "default"},
+		{name: "expected depth", options: []gormx.Option{gormx.WithExpectedDepth(4)}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			db := createConnection(b)
+			gx, _ := gormx.New(db, bm.options...)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for d := 0; d < 4; d++ {
+					gx.BeginTxx(ctx)
+				}
+				for d := 0; d < 4; d++ {
+					gx.Commitx()
+				}
+			}
+		})
+	}
+}
+
+func TestWithSavepointPrefix_ReservedWord(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	gx, err := gormx.New(db,
+		gormx.WithSavepointPrefix("select-"),
+		gormx.WithMaxSavepointNameLength(15),
+	)
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(nested.Rollbackx())
+	assert.NoError(tx.Exec("INSERT INTO t2(id) VALUES('abc')").Error)
+	assert.NoError(tx.Commitx())
+
+	var savepoints, rollbacks int
+	for _, stmt := range rec.Statements() {
+		if strings.HasPrefix(stmt, "SAVEPOINT ") {
+			assert.Regexp("^SAVEPOINT `select-[0-9a-f]{8}`$", stmt)
+			savepoints++
+		}
+		if strings.HasPrefix(stmt, "ROLLBACK TO SAVEPOINT ") {
+			assert.Regexp("^ROLLBACK TO SAVEPOINT `select-[0-9a-f]{8}`$", stmt)
+			rollbacks++
+		}
+	}
+	assert.Equal(2, savepoints)
+	assert.Equal(1, rollbacks)
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 1)
+}
+
+func TestWithSavepointSQL_FailedTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	gx, _ := gormx.New(db, gormx.WithSavepointSQL(
+		func(name string) string { return "SAVEPOINT " + name },
+		func(name string) string { return "ROLLBACK TO SAVEPOINT " + name },
+		nil,
+	))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.AddError(errors.New("failure"))
+
+	// the error of the transaction is reported once
+	_, err := gx.BeginTxxE(ctx)
+	assert.EqualError(err, "failure")
+	gx.BeginTxx(ctx)
+	assert.EqualError(gx.Err(), "failure")
+
+	assert.NoError(gx.Rollbackx())
+	assert.NoError(tx.Rollbackx())
+}
+
+func TestWithSavepointSQL(t *testing.T) {
+	assert := assert.New(t)
+	rec := &recordingLogger{}
+	db := createConnection(t).Session(&gorm.Session{Logger: rec})
+
+	gx, err := gormx.New(db, gormx.WithSavepointSQL(
+		func(name string) string { return "SAVEPOINT /* begin */ " + name },
+		func(name string) string { return "ROLLBACK /* rollback */ TO SAVEPOINT " + name },
+		func(name string) string { return "RELEASE /* release */ SAVEPOINT " + name },
+	))
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.NoError(nested.Rollbackx())
+	nested = gx.BeginTxx(ctx)
+	assert.NoError(nested.Exec("INSERT INTO t2(id) VALUES('abc')").Error)
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+
+	var ops []string
+	for _, stmt := range rec.Statements() {
+		if m := regexp.MustCompile(`/\* (\w+) \*/ (TO SAVEPOINT |SAVEPOINT )?sp_\w+$`).FindStringSubmatch(stmt); m != nil {
+			ops = append(ops, m[1])
+		}
+	}
+	assert.Equal([]string{"begin", "begin", "rollback", "begin", "release"}, ops)
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 1)
+
+	_, err = gormx.New(db, gormx.WithSavepointSQL(nil, nil, nil))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
+
+func TestWithSavepointCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	db := createConnectionWithoutSavePoints(t)
+	_, err := gormx.New(db, gormx.WithSavepointCheck())
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+
+	// custom savepoint statements make up for the dialector
+	gx, err := gormx.New(db,
+		gormx.WithSavepointCheck(),
+		gormx.WithSavepointSQL(
+			func(name string) string { return "SAVEPOINT " + name },
+			func(name string) string { return "ROLLBACK TO SAVEPOINT " + name },
+			nil,
+		),
+	)
+	assert.NoError(err)
+	gx.Close()
+
+	db = createConnection(t)
+	gx, err = gormx.New(db, gormx.WithSavepointCheck())
+	assert.NoError(err)
+	gx.Close()
+}
+
+func TestGormx_ExecIn(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	assert.ErrorIs(gx.ExecIn(1).Error, gormx.ErrInvalidDepth)
+
+	tx := gx.BeginTxx(ctx)
+	nested := gx.BeginTxx(ctx)
+
+	assert.NoError(gx.ExecIn(1).Exec("INSERT INTO t1(id) VALUES('abc')").Error)
+	assert.ErrorIs(gx.ExecIn(3).Exec("INSERT INTO t2(id) VALUES('abc')").Error, gormx.ErrInvalidDepth)
+	assert.ErrorIs(gx.ExecIn(0).Error, gormx.ErrInvalidDepth)
+
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+}
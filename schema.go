@@ -0,0 +1,44 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingTables is returned by New and Connect, with WithSchemaCheck, when
+// some of the expected tables don't exist.
+var ErrMissingTables = errors.New("missing tables")
+
+// WithSchemaCheck makes New and Connect check that the given tables exist in
+// the database, failing with ErrMissingTables naming the missing ones
+// otherwise. It catches a deployment pointed at the wrong or a non-migrated
+// database up front, rather than at its first query.
+func WithSchemaCheck(tables ...string) Option {
+	return func(g *gormx) error {
+		g.schemaTables = append(g.schemaTables, tables...)
+		return nil
+	}
+}
+
+// checkSchema returns ErrMissingTables if any of the tables of
+// WithSchemaCheck doesn't exist.
+func (g *gormx) checkSchema() error {
+	if len(g.schemaTables) == 0 {
+		return nil
+	}
+
+	migrator := g.db.WithContext(g.defaultCtx).Migrator()
+
+	var missing []string
+	for _, table := range g.schemaTables {
+		if !migrator.HasTable(table) {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrMissingTables, strings.Join(missing, ", "))
+}
@@ -0,0 +1,27 @@
+package gormx_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestConnect_WithSchemaCheck(t *testing.T) {
+	assert := assert.New(t)
+	createConnection(t)
+	dataSource := fmt.Sprintf("gormx:gormx@tcp(localhost:%s)/gormx?charset=utf8mb4&parseTime=true", strconv.FormatInt(port, 10))
+
+	gx, err := gormx.Connect(dataSource, &gorm.Config{}, gormx.WithSchemaCheck("t1", "missing_table"))
+	assert.Nil(gx)
+	assert.ErrorIs(err, gormx.ErrMissingTables)
+	assert.EqualError(err, "missing tables: missing_table")
+
+	gx, err = gormx.Connect(dataSource, &gorm.Config{}, gormx.WithSchemaCheck("t1", "t2"))
+	if assert.NoError(err) {
+		gx.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidContext is returned when a nil context is given as default
+// context, or to begin a transaction in strict mode.
+var ErrInvalidContext = errors.New("invalid context")
+
+// sessionInit prepares the session of a newly begun top-level transaction, or
+// restores it before the transaction resolves.
+type sessionInit func(tx *gorm.DB) error
+
+// WithStatementTimeout makes the database abort any statement of a gormx
+// transaction running longer than d. It sets statement_timeout locally to the
+// transaction on Postgres. MySQL has no transaction-local variables, so the
+// session max_execution_time is set when the transaction begins and restored
+// before it resolves, for the pooled connection not to keep it.
+func WithStatementTimeout(d time.Duration) Option {
+	return func(g *gormx) error {
+		switch g.dialect() {
+		case "mysql":
+			init := fmt.Sprintf("SET @gormx_max_execution_time = @@SESSION.max_execution_time, SESSION max_execution_time = %d", d.Milliseconds())
+			g.sessionInits = append(g.sessionInits, func(tx *gorm.DB) error {
+				return tx.Exec(init).Error
+			})
+			g.sessionResets = append(g.sessionResets, func(tx *gorm.DB) error {
+				return tx.Exec("SET SESSION max_execution_time = @gormx_max_execution_time").Error
+			})
+		case "postgres":
+			init := fmt.Sprintf("SET LOCAL statement_timeout = %d", d.Milliseconds())
+			g.sessionInits = append(g.sessionInits, func(tx *gorm.DB) error {
+				return tx.Exec(init).Error
+			})
+		default:
+			return ErrIncompatibleOption
+		}
+		return nil
+	}
+}
+
+// WithReadTimeout bounds the reads made by the query helpers outside of a
+// transaction, such as Raw, QueryMaps and the reads of Repository, to d when
+// their context has no deadline of its own. Statements run in transactions
+// are bounded by WithStatementTimeout instead, as cancelling one of them
+// would break the transaction.
+func WithReadTimeout(d time.Duration) Option {
+	return func(g *gormx) error {
+		if d <= 0 {
+			return ErrIncompatibleOption
+		}
+		g.readTimeout = d
+		return nil
+	}
+}
+
+// initSession runs the session initialisers against the current transaction.
+func (g *gormx) initSession() {
+	g.internal = true
+	defer func() { g.internal = false }()
+
+	for _, init := range g.sessionInits {
+		if err := init(g.DB); err != nil {
+			g.DB.AddError(err)
+			return
+		}
+	}
+}
+
+// resetSession restores the session state changed by the session
+// initialisers, before the connection of the current transaction returns to
+// the pool. It runs even if the transaction failed, and a failure is only
+// logged, as the transaction must resolve anyway.
+func (g *gormx) resetSession() {
+	if len(g.sessionResets) == 0 {
+		return
+	}
+
+	g.internal = true
+	defer func() { g.internal = false }()
+
+	tx := g.DB.Session(&gorm.Session{})
+	tx.Error = nil
+	for _, reset := range g.sessionResets {
+		if err := reset(tx); err != nil {
+			g.db.Logger.Warn(tx.Statement.Context, "gormx: resetting the session: %s", err)
+		}
+	}
+}
+
+// Session returns the transaction handle, or the gorm DB outside of a
+// transaction, with the session options opts applied, e.g. to enable
+// FullSaveAssociations for a single operation without reconfiguring the
+// connection.
+func (g *gormx) Session(opts *gorm.Session) *gorm.DB {
+	if !g.inTransaction() {
+		return g.db.Session(opts)
+	}
+	return g.DB.Session(opts)
+}
+
+func (g *gormx) dialect() string {
+	return g.db.Dialector.Name()
+}
+
+// WithDefaultContext sets the context used by operations that aren't given
+// one, such as Beginx, instead of context.Background. It lets values such as
+// a tenant or trace ID reach gorm callbacks and loggers.
+func WithDefaultContext(ctx context.Context) Option {
+	return func(g *gormx) error {
+		if ctx == nil {
+			return ErrInvalidContext
+		}
+		g.defaultCtx = ctx
+		return nil
+	}
+}
+
+// context returns ctx, or the default context if ctx is nil. In strict mode,
+// a nil ctx is an error instead.
+func (g *gormx) context(ctx context.Context) (context.Context, error) {
+	if ctx != nil {
+		return ctx, nil
+	}
+	if g.strict {
+		return nil, ErrInvalidContext
+	}
+	return g.defaultCtx, nil
+}
@@ -0,0 +1,158 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWithStatementTimeout(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	db.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	gx, err := gormx.New(db, gormx.WithStatementTimeout(100*time.Millisecond))
+	assert.NoError(err)
+	defer gx.Close()
+
+	tx := gx.BeginTxx(context.Background())
+	defer tx.Rollbackx()
+
+	var slept []int
+	err = tx.Raw("SELECT SLEEP(1) FROM t1").Scan(&slept).Error
+	assert.ErrorContains(err, "maximum statement execution time exceeded")
+}
+
+func TestWithStatementTimeout_Reset(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name  string
+		open  func(t testing.TB) *gorm.DB
+		query string
+	}
+
+	testCases := []testCase{
+		{name: "mysql", open: createConnection, query: "SELECT @@SESSION.max_execution_time"},
+		{name: "postgres", open: createPostgresConnection, query: "SELECT current_setting('statement_timeout')"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := tc.open(t)
+
+			// a single connection, to check the state it returns to the pool with
+			sqlDB, _ := db.DB()
+			sqlDB.SetMaxOpenConns(1)
+
+			gx, err := gormx.New(db, gormx.WithStatementTimeout(100*time.Millisecond))
+			assert.NoError(err)
+			defer gx.Close()
+
+			var before, during, after string
+			db.Raw(tc.query).Scan(&before)
+
+			tx := gx.BeginTxx(context.Background())
+			tx.Raw(tc.query).Scan(&during)
+			assert.NoError(tx.Commitx())
+
+			db.Raw(tc.query).Scan(&after)
+			assert.NotEqual(before, during)
+			assert.Equal(before, after)
+		})
+	}
+}
+
+type ctxKey struct{}
+
+func TestWithDefaultContext(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var seen any
+	db.Callback().Raw().Before("gorm:raw").Register("gormx_test:context", func(db *gorm.DB) {
+		seen = db.Statement.Context.Value(ctxKey{})
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "tenant")
+	gx, err := gormx.New(db, gormx.WithDefaultContext(ctx))
+	assert.NoError(err)
+	defer gx.Close()
+
+	tx := gx.Beginx()
+	defer tx.Rollbackx()
+
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+	assert.Equal("tenant", seen)
+}
+
+func TestGormx_Session(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	t7 := models.T7{ID: "a", T8: models.T8{ID: "b", Name: "created"}}
+	assert.NoError(gx.Session(&gorm.Session{}).Create(&t7).Error)
+
+	tx := gx.BeginTxx(context.Background())
+
+	// by default, the associations of a saved record are only inserted
+	t7.T8.Name = "ignored"
+	assert.NoError(tx.Save(&t7).Error)
+
+	var t8 models.T8
+	tx.First(&t8, "id = ?", "b")
+	assert.Equal("created", t8.Name)
+
+	t7.T8.Name = "updated"
+	assert.NoError(gx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&t7).Error)
+
+	tx.First(&t8, "id = ?", "b")
+	assert.Equal("updated", t8.Name)
+
+	// the option only applied to that call
+	t7.T8.Name = "ignored"
+	assert.NoError(tx.Save(&t7).Error)
+
+	tx.First(&t8, "id = ?", "b")
+	assert.Equal("updated", t8.Name)
+
+	assert.NoError(tx.Commitx())
+}
+
+func TestWithReadTimeout(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	gx, err := gormx.New(db, gormx.WithReadTimeout(100*time.Millisecond))
+	assert.NoError(err)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	start := time.Now()
+	_, err = gormx.Raw[int](ctx, gx, "SELECT SLEEP(1)")
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), time.Second)
+
+	// a deadline of the caller takes precedence
+	longer, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	_, err = gormx.Raw[int](longer, gx, "SELECT SLEEP(0.2)")
+	assert.NoError(err)
+
+	// and reads in transactions are left alone
+	tx := gx.BeginTxx(ctx)
+	_, err = gormx.Raw[int](ctx, tx, "SELECT SLEEP(0.2)")
+	assert.NoError(err)
+	assert.NoError(tx.Commitx())
+
+	_, err = gormx.New(db, gormx.WithReadTimeout(0))
+	assert.ErrorIs(err, gormx.ErrIncompatibleOption)
+}
@@ -0,0 +1,72 @@
+package gormx
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxBeginStackDepth is the maximum number of frames captured by
+// WithCaptureBeginStack.
+const maxBeginStackDepth = 32
+
+// WithCaptureBeginStack captures the stack where each top-level transaction
+// is begun, and appends it to the errors returned by Commitx and Rollbackx
+// when resolving it, to find where a failed transaction comes from.
+//
+// Capturing the stack has a cost on every begin, so it is disabled by
+// default.
+func WithCaptureBeginStack() Option {
+	return func(g *gormx) error {
+		g.captureStack = true
+		return nil
+	}
+}
+
+// beginStackError appends the stack a transaction was begun at to an error.
+type beginStackError struct {
+	err   error
+	stack string
+}
+
+func (e *beginStackError) Error() string {
+	return e.err.Error() + "\ntransaction begun at:\n" + e.stack
+}
+
+func (e *beginStackError) Unwrap() error {
+	return e.err
+}
+
+// withBeginStack returns err with the begin stack of the transaction, if
+// captured.
+func (g *gormx) withBeginStack(err error) error {
+	if err == nil || g.beginStack == "" {
+		return err
+	}
+	return &beginStackError{err: err, stack: g.beginStack}
+}
+
+// callerStack returns the stack of the caller of gormx, formatted like a
+// panic stack trace without the gormx frames.
+func callerStack() string {
+	pcs := make([]uintptr, maxBeginStackDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/pnuggz/gormx.") {
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+			b.WriteByte('\n')
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCaptureBeginStack(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithCaptureBeginStack())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	// the connection of the transaction is killed, failing the commit
+	tx.Exec("KILL CONNECTION_ID()")
+
+	err := tx.Commitx()
+	if assert.Error(err) {
+		assert.ErrorIs(err, gormx.ErrConnectionLost)
+		assert.Contains(err.Error(), "transaction begun at:")
+		assert.Contains(err.Error(), "TestWithCaptureBeginStack")
+		assert.Contains(err.Error(), "stack_test.go")
+		assert.NotContains(err.Error(), "gormx.(*gormx)")
+	}
+}
@@ -0,0 +1,45 @@
+package gormx
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// txState is the JSON representation of the transaction state of a gormx.
+type txState struct {
+	Active       bool       `json:"active"`
+	Dialect      string     `json:"dialect"`
+	Depth        int        `json:"depth"`
+	CommitCount  int        `json:"commit_count"`
+	SavepointIDs []string   `json:"savepoint_ids"`
+	BeginTime    *time.Time `json:"begin_time,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	RequestID    string     `json:"request_id,omitempty"`
+}
+
+// MarshalState returns the state of the transaction open on g as a JSON
+// object, e.g. for a debug endpoint: whether one is open, the dialect, the
+// nesting depth, the number of nested transactions committed and the IDs of
+// the savepoints. The begin time, and the name and request ID of the
+// transaction if set, are only included while it is open.
+func (g *gormx) MarshalState() ([]byte, error) {
+	state := txState{
+		Active:       g.inTransaction(),
+		Dialect:      g.dialect(),
+		SavepointIDs: []string{},
+	}
+
+	if state.Active {
+		beginTime := g.beginTime
+		state.Depth = g.transactionCount - g.commitCount
+		state.CommitCount = g.commitCount
+		for _, id := range g.savePointIDs {
+			state.SavepointIDs = append(state.SavepointIDs, savePointName(id))
+		}
+		state.BeginTime = &beginTime
+		state.Name = g.name
+		state.RequestID = g.requestID
+	}
+
+	return json.Marshal(state)
+}
@@ -0,0 +1,47 @@
+package gormx_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_MarshalState(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db, gormx.WithSavepointPrefix("state_"))
+	defer gx.Close()
+
+	data, err := gx.MarshalState()
+	assert.NoError(err)
+	assert.JSONEq(`{"active":false,"dialect":"mysql","depth":0,"commit_count":0,"savepoint_ids":[]}`, string(data))
+
+	ctx := context.Background()
+	tx := gx.BeginTxxNamed(ctx, "checkout")
+	nested := gx.BeginTxx(ctx)
+	assert.NoError(gx.BeginTxx(ctx).Commitx())
+
+	data, err = gx.MarshalState()
+	assert.NoError(err)
+
+	var state map[string]any
+	assert.NoError(json.Unmarshal(data, &state))
+	assert.Equal(true, state["active"])
+	assert.Equal("mysql", state["dialect"])
+	assert.Equal(float64(2), state["depth"])
+	assert.Equal(float64(1), state["commit_count"])
+	assert.Equal("checkout", state["name"])
+	assert.NotEmpty(state["begin_time"])
+
+	var ids []any
+	for _, sp := range gx.ActiveSavepoints() {
+		ids = append(ids, sp.ID)
+	}
+	assert.Equal(ids, state["savepoint_ids"])
+
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+}
@@ -0,0 +1,134 @@
+package gormx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrHookPanicked is returned by Commitx and Rollbackx when commit or rollback
+// hooks panicked. The transaction is resolved nonetheless.
+var ErrHookPanicked = errors.New("transaction hook panicked")
+
+// TxStats summarises the work done by a top-level transaction.
+type TxStats struct {
+	// Statements is the number of statements run in the transaction.
+	Statements int
+	// RowsAffected is the total number of rows affected by writes.
+	RowsAffected int64
+	// RowsScanned is the total number of rows returned by queries.
+	RowsScanned int64
+	// Duration is the time elapsed between the top-level begin and
+	// the commit or rollback.
+	Duration time.Duration
+	// Tags are the labels derived from the context of the transaction by the
+	// WithTagsFromContext function, if any.
+	Tags map[string]string
+}
+
+// txHook is a commit or rollback hook.
+type txHook struct {
+	priority int
+	fn       func(TxStats)
+}
+
+// addHook inserts fn in hooks, after the hooks of lower or equal priority.
+func addHook(hooks []txHook, priority int, fn func(TxStats)) []txHook {
+	i := len(hooks)
+	for i > 0 && hooks[i-1].priority > priority {
+		i--
+	}
+
+	hooks = append(hooks, txHook{})
+	copy(hooks[i+1:], hooks[i:])
+	hooks[i] = txHook{priority: priority, fn: fn}
+	return hooks
+}
+
+// OnCommit registers fn to be called with the transaction stats
+// after every top-level commit. Hooks run in the order they are registered
+// in, and a panicking hook doesn't prevent the next ones from running.
+func OnCommit(fn func(TxStats)) Option {
+	return OnCommitPriority(0, fn)
+}
+
+// OnCommitPriority registers fn like OnCommit, running it in ascending order
+// of priority among the commit hooks. OnCommit hooks have priority 0, and
+// hooks of equal priority run in the order they are registered in.
+func OnCommitPriority(priority int, fn func(TxStats)) Option {
+	return func(g *gormx) error {
+		g.onCommit = addHook(g.onCommit, priority, fn)
+		return nil
+	}
+}
+
+// OnRollback registers fn to be called with the transaction stats
+// after every top-level rollback. Hooks run in the order they are
+// registered in, and a panicking hook doesn't prevent the next ones from
+// running.
+func OnRollback(fn func(TxStats)) Option {
+	return func(g *gormx) error {
+		g.onRollback = addHook(g.onRollback, 0, fn)
+		return nil
+	}
+}
+
+// WithTagsFromContext sets fn to derive tags, such as the endpoint serving the
+// request, from the context each top-level transaction begins with. They are
+// passed to the commit and rollback hooks in TxStats.Tags, e.g. to label
+// metrics.
+func WithTagsFromContext(fn func(ctx context.Context) map[string]string) Option {
+	return func(g *gormx) error {
+		g.tagsFn = fn
+		return nil
+	}
+}
+
+// runHooks calls hooks with the stats of the resolved transaction. It
+// returns an ErrHookPanicked error listing the panics of hooks, if any.
+func (g *gormx) runHooks(hooks []txHook) error {
+	stats := g.stats
+	stats.Duration = time.Since(g.beginTime)
+	g.durations.add(stats.Duration)
+
+	var panics []string
+	for _, hook := range hooks {
+		if p := runHook(hook.fn, stats); p != nil {
+			panics = append(panics, fmt.Sprint(p))
+		}
+	}
+
+	if len(panics) > 0 {
+		return fmt.Errorf("%w: %s", ErrHookPanicked, strings.Join(panics, "; "))
+	}
+	return nil
+}
+
+// runHook calls fn with stats, returning the value it panicked with if any.
+func runHook(fn func(TxStats), stats TxStats) (p any) {
+	defer func() { p = recover() }()
+	fn(stats)
+	return nil
+}
+
+// TotalRowsAffected returns the total number of rows affected by the writes
+// run so far in the current top-level transaction, or in the last one if none
+// is open, e.g. to confirm the changes before committing them. Rows written
+// inside nested transactions that were rolled back are still counted. It
+// isn't named RowsAffected, so as not to shadow the field of the embedded
+// gorm DB.
+func (g *gormx) TotalRowsAffected() int64 {
+	return g.stats.RowsAffected
+}
+
+// StatementCount returns the number of statements run so far in the current
+// top-level transaction, e.g. to catch N+1 query patterns. It returns 0
+// outside of a transaction.
+func (g *gormx) StatementCount() int {
+	if !g.inTransaction() {
+		return 0
+	}
+	return g.stats.Statements
+}
@@ -0,0 +1,196 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxStats(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var committed []gormx.TxStats
+	gx, _ := gormx.New(db, gormx.OnCommit(func(stats gormx.TxStats) {
+		committed = append(committed, stats)
+	}))
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('a'), ('b')")
+	tx.Exec("INSERT INTO t1(id) VALUES('c')")
+	tx.Exec("UPDATE t1 SET id = 'd' WHERE id = 'c'")
+
+	var t1s []models.T1
+	tx.Find(&t1s)
+	tx.Commitx()
+
+	assert.Len(committed, 1)
+	assert.Equal(4, committed[0].Statements)
+	assert.Equal(int64(4), committed[0].RowsAffected)
+	assert.Equal(int64(3), committed[0].RowsScanned)
+	assert.NotZero(committed[0].Duration)
+}
+
+type tagsKey struct{}
+
+func TestWithTagsFromContext(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	// stub metrics sink, labelling the transaction count
+	sink := map[string]int{}
+	record := func(stats gormx.TxStats) {
+		sink[stats.Tags["endpoint"]]++
+	}
+
+	gx, _ := gormx.New(db,
+		gormx.WithTagsFromContext(func(ctx context.Context) map[string]string {
+			endpoint, _ := ctx.Value(tagsKey{}).(string)
+			return map[string]string{"endpoint": endpoint}
+		}),
+		gormx.OnCommit(record),
+		gormx.OnRollback(record),
+	)
+	defer gx.Close()
+
+	ctx := context.WithValue(context.Background(), tagsKey{}, "/orders")
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('a')")
+	tx.Commitx()
+
+	tx = gx.BeginTxx(ctx)
+	tx.Rollbackx()
+
+	tx = gx.BeginTxx(context.WithValue(context.Background(), tagsKey{}, "/users"))
+	tx.Commitx()
+
+	assert.Equal(map[string]int{"/orders": 2, "/users": 1}, sink)
+}
+
+func TestGormx_DurationStats(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	assert.Equal(gormx.DurationSummary{}, gx.DurationStats())
+
+	ctx := context.Background()
+
+	for i := 1; i <= 20; i++ {
+		tx := gx.BeginTxx(ctx)
+		time.Sleep(time.Duration(i) * time.Millisecond)
+		if i%2 == 0 {
+			tx.Commitx()
+		} else {
+			tx.Rollbackx()
+		}
+	}
+
+	summary := gx.DurationStats()
+	assert.Equal(20, summary.Count)
+	assert.GreaterOrEqual(summary.P50, 10*time.Millisecond)
+	assert.LessOrEqual(summary.P50, summary.P95)
+	assert.LessOrEqual(summary.P95, summary.P99)
+	assert.LessOrEqual(summary.P99, summary.Max)
+	assert.GreaterOrEqual(summary.Max, 20*time.Millisecond)
+}
+
+func TestGormx_TotalRowsAffected(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('a'), ('b')")
+	assert.Equal(int64(2), gx.TotalRowsAffected())
+
+	nested := gx.BeginTxx(ctx)
+	nested.Create(&models.T2{ID: "c"})
+	nested.Exec("UPDATE t1 SET id = CONCAT(id, 'x')")
+	nested.Commitx()
+
+	var t1s []models.T1
+	tx.Find(&t1s)
+	assert.Equal(int64(5), gx.TotalRowsAffected())
+	tx.Commitx()
+
+	// a new top-level transaction starts counting afresh
+	tx = gx.BeginTxx(ctx)
+	assert.Zero(gx.TotalRowsAffected())
+	tx.Exec("DELETE FROM t2")
+	assert.Equal(int64(1), gx.TotalRowsAffected())
+	tx.Commitx()
+}
+
+func TestOnCommitPriority(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	var order []string
+	hook := func(name string) func(gormx.TxStats) {
+		return func(gormx.TxStats) {
+			order = append(order, name)
+		}
+	}
+
+	gx, _ := gormx.New(db,
+		gormx.OnCommit(hook("first")),
+		gormx.OnCommitPriority(10, hook("last")),
+		gormx.OnCommit(func(gormx.TxStats) {
+			order = append(order, "panicking")
+			panic("boom")
+		}),
+		gormx.OnCommitPriority(-10, hook("early")),
+		gormx.OnCommit(hook("second")),
+	)
+	defer gx.Close()
+
+	tx := gx.BeginTxx(context.Background())
+	tx.Exec("INSERT INTO t1(id) VALUES('a')")
+	err := tx.Commitx()
+	assert.ErrorIs(err, gormx.ErrHookPanicked)
+	assert.ErrorContains(err, "boom")
+	assert.Equal([]string{"early", "first", "panicking", "second", "last"}, order)
+
+	// the transaction is committed despite the panic
+	var t1s []models.T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+}
+
+func TestGormx_StatementCount(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	assert.Zero(gx.StatementCount())
+
+	// savepoints are not counted, only the statements run through tx
+	nested := gx.BeginTxx(ctx)
+	for i := 0; i < 5; i++ {
+		var t1 models.T1
+		nested.Where("id = ?", i).Limit(1).Find(&t1)
+	}
+	nested.Exec("INSERT INTO t1(id) VALUES('a')")
+	assert.NoError(nested.Commitx())
+	assert.Equal(6, gx.StatementCount())
+
+	assert.NoError(tx.Commitx())
+	assert.Zero(gx.StatementCount())
+}
@@ -0,0 +1,67 @@
+package gormx
+
+import "context"
+
+const defaultStreamBatchSize = 500
+
+// InsertStream inserts the records received on ch in a transaction of gx,
+// nested in the active one if any, in batches of up to batchSize records. The
+// last batch is flushed once ch is closed, and the transaction committed. It
+// returns the number of records inserted.
+//
+// The transaction is rolled back if an insert fails or ctx is done before ch
+// is closed, in which case nothing is inserted and the error is returned.
+// batchSize defaults to 500.
+func InsertStream[T any](ctx context.Context, gx Gormx, ch <-chan T, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultStreamBatchSize
+	}
+
+	tx, err := gx.BeginTxxE(ctx)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return 0, err
+	}
+
+	var inserted int64
+	batch := make([]T, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		res := handle(ctx, tx).Create(&batch)
+		inserted += res.RowsAffected
+		batch = batch[:0]
+		return translateError(res.Error)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			tx.Rollbackx()
+			return 0, ctx.Err()
+		case record, ok := <-ch:
+			if !ok {
+				if err := flush(); err != nil {
+					tx.Rollbackx()
+					return 0, err
+				}
+				if err := tx.Commitx(); err != nil {
+					return 0, err
+				}
+				return inserted, nil
+			}
+
+			batch = append(batch, record)
+			if len(batch) < batchSize {
+				continue
+			}
+			if err := flush(); err != nil {
+				tx.Rollbackx()
+				return 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gormx_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/pnuggz/gormx/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertStream(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ch := make(chan models.T1)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 1000; i++ {
+			ch <- models.T1{ID: fmt.Sprintf("id-%04d", i)}
+		}
+	}()
+
+	inserted, err := gormx.InsertStream(context.Background(), gx, ch, 64)
+	assert.NoError(err)
+	assert.Equal(int64(1000), inserted)
+
+	var count int64
+	gx.Gorm().Model(&models.T1{}).Count(&count)
+	assert.Equal(int64(1000), count)
+	assert.Zero(gx.OpenTransactions())
+}
+
+func TestInsertStream_Canceled(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ch := make(chan models.T1)
+	go func() {
+		for i := 0; i < 10; i++ {
+			ch <- models.T1{ID: fmt.Sprintf("id-%04d", i)}
+		}
+		// the channel is left open, the stream only ends on cancellation
+		cancel()
+	}()
+
+	inserted, err := gormx.InsertStream(ctx, gx, ch, 4)
+	assert.ErrorIs(err, context.Canceled)
+	assert.Zero(inserted)
+
+	var count int64
+	gx.Gorm().Model(&models.T1{}).Count(&count)
+	assert.Zero(count)
+	assert.Zero(gx.OpenTransactions())
+}
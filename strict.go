@@ -0,0 +1,80 @@
+package gormx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionSpent is returned when beginning a transaction on a Gormx
+// whose transaction was resolved, with WithSingleUse.
+var ErrTransactionSpent = errors.New("transaction spent")
+
+// ErrOpenTransactions is returned by Close, with WithStrictClose, when
+// transactions are still open.
+var ErrOpenTransactions = errors.New("transactions still open")
+
+// WithStrictMode makes any error raised by the SQL gormx runs to manage
+// transactions fatal: BeginTxx, Commitx and Rollbackx panic when beginning,
+// creating, rolling back to a savepoint, committing or rolling back fails,
+// instead of leaving the error on the transaction. Use BeginTxxE to get the
+// error back rather than a panic when beginning.
+func WithStrictMode() Option {
+	return func(g *gormx) error {
+		g.strict = true
+		return nil
+	}
+}
+
+// mustSucceed panics with err in strict mode.
+func (g *gormx) mustSucceed(err error) {
+	if err != nil && g.strict {
+		panic(err)
+	}
+}
+
+// WithStrictClose makes Close return ErrOpenTransactions when top-level
+// transactions begun by the Gormx are still open, revealing code that forgot
+// to commit or roll them back. The connection is closed nonetheless. Without
+// it, Close only logs a warning.
+func WithStrictClose() Option {
+	return func(g *gormx) error {
+		g.strictClose = true
+		return nil
+	}
+}
+
+// checkOpenTransactions reports the transactions still open when closing g,
+// as an error with WithStrictClose and as a warning otherwise.
+func (g *gormx) checkOpenTransactions() error {
+	n := g.OpenTransactions()
+	if n == 0 {
+		return nil
+	}
+
+	if g.strictClose {
+		return fmt.Errorf("%w: %d transactions, %d savepoints", ErrOpenTransactions, n, len(g.savePointIDs))
+	}
+	g.db.Logger.Warn(g.defaultCtx, "gormx: closing with %d transactions still open, %d savepoints", n, len(g.savePointIDs))
+	return nil
+}
+
+// WithSingleUse makes the Gormx single-use: once its top-level transaction
+// has begun, beginning another one after it resolves returns
+// ErrTransactionSpent, and a new Gormx must be created for it, e.g. with New
+// on the same gorm DB. It trades the convenience of reusing a Gormx for
+// safety against state leaking from one transaction to the next.
+func WithSingleUse() Option {
+	return func(g *gormx) error {
+		g.singleUse = true
+		return nil
+	}
+}
+
+// checkSpent returns ErrTransactionSpent when beginning a new top-level
+// transaction on a spent single-use g.
+func (g *gormx) checkSpent() error {
+	if g.singleUse && g.used && !g.inTransaction() {
+		return ErrTransactionSpent
+	}
+	return nil
+}
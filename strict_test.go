@@ -0,0 +1,94 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWithStrictMode(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name       string
+		options    []gormx.Option
+		assertions func(gormx.Gormx)
+	}
+
+	testCases := []testCase{
+		{
+			name: "lenient",
+			assertions: func(gx gormx.Gormx) {
+				assert.NotPanics(func() {
+					tx := gx.BeginTxx(context.Background())
+					assert.ErrorIs(tx.Tx().Error, gorm.ErrUnsupportedDriver)
+				})
+			},
+		},
+		{
+			name:    "strict",
+			options: []gormx.Option{gormx.WithStrictMode()},
+			assertions: func(gx gormx.Gormx) {
+				assert.PanicsWithValue(gorm.ErrUnsupportedDriver, func() {
+					gx.BeginTxx(context.Background())
+				})
+			},
+		},
+		{
+			name:    "strict with error",
+			options: []gormx.Option{gormx.WithStrictMode()},
+			assertions: func(gx gormx.Gormx) {
+				assert.NotPanics(func() {
+					_, err := gx.BeginTxxE(context.Background())
+					assert.ErrorIs(err, gorm.ErrUnsupportedDriver)
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gx, _ := gormx.New(createConnectionWithoutSavePoints(t), tc.options...)
+			defer gx.Close()
+
+			tc.assertions(gx)
+		})
+	}
+}
+
+func TestWithSingleUse(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+
+	gx, _ := gormx.New(db, gormx.WithSingleUse())
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx, err := gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	nested, err := gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	assert.NoError(nested.Commitx())
+	assert.NoError(tx.Commitx())
+
+	_, err = gx.BeginTxxE(ctx)
+	assert.ErrorIs(err, gormx.ErrTransactionSpent)
+	_, err = gx.BeginTxxOnConn(ctx)
+	assert.ErrorIs(err, gormx.ErrTransactionSpent)
+
+	spent := gx.BeginTxx(ctx)
+	if assert.NotNil(spent) {
+		assert.ErrorIs(spent.Err(), gormx.ErrTransactionSpent)
+		assert.ErrorIs(spent.Exec("INSERT INTO t1(id) VALUES('abc')").Error, gormx.ErrTransactionSpent)
+	}
+
+	// a fresh instance is needed
+	gx, _ = gormx.New(db, gormx.WithSingleUse())
+	tx, err = gx.BeginTxxE(ctx)
+	assert.NoError(err)
+	assert.NoError(tx.Rollbackx())
+}
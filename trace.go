@@ -0,0 +1,40 @@
+package gormx
+
+// TraceOp is a transaction operation recorded in a trace.
+type TraceOp string
+
+const (
+	// TraceBegin records a BeginTxx.
+	TraceBegin TraceOp = "begin"
+	// TraceCommit records a Commitx.
+	TraceCommit TraceOp = "commit"
+	// TraceRollback records a Rollbackx.
+	TraceRollback TraceOp = "rollback"
+	// TraceCheckpoint records a Checkpoint.
+	TraceCheckpoint TraceOp = "checkpoint"
+)
+
+// TraceEntry is an operation recorded in a trace.
+type TraceEntry struct {
+	Op          TraceOp
+	SavepointID string
+	// Depth is the nesting depth of the transaction the operation applies
+	// to, 1 being the top-level transaction.
+	Depth int
+}
+
+// Trace returns the ordered log of the begin, commit and rollback operations
+// made since the current top-level transaction began, or of the last one if
+// none is open. Unlike hooks, it is meant to be inspected afterwards, e.g. to
+// debug nested flows in tests.
+func (g *gormx) Trace() []TraceEntry {
+	return append([]TraceEntry(nil), g.trace...)
+}
+
+func (g *gormx) addTrace(op TraceOp, savePointID string) {
+	g.trace = append(g.trace, TraceEntry{
+		Op:          op,
+		SavepointID: savePointName(savePointID),
+		Depth:       g.transactionCount - g.commitCount,
+	})
+}
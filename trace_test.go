@@ -0,0 +1,65 @@
+package gormx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGormx_Trace(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	txService := gx.BeginTxx(ctx)
+
+	tx1 := gx.BeginTxx(ctx)
+	tx1.Exec("INSERT INTO t1(id) VALUES('abc')")
+	tx1.Commitx()
+
+	tx2 := gx.BeginTxx(ctx)
+	tx2.Exec("INSERT INTO t2(id) VALUES('abc')")
+	tx2.Commitx()
+
+	tx3 := gx.BeginTxx(ctx)
+	tx3.Exec("INSERT INTO t3(id) VALUES('abc')")
+	tx3.Rollbackx()
+
+	txService.Commitx()
+
+	trace := gx.Trace()
+
+	type step struct {
+		op    gormx.TraceOp
+		depth int
+	}
+	var steps []step
+	for _, entry := range trace {
+		steps = append(steps, step{entry.Op, entry.Depth})
+	}
+
+	assert.Equal([]step{
+		{gormx.TraceBegin, 1},
+		{gormx.TraceBegin, 2},
+		{gormx.TraceCommit, 2},
+		{gormx.TraceBegin, 2},
+		{gormx.TraceCommit, 2},
+		{gormx.TraceBegin, 2},
+		{gormx.TraceRollback, 2},
+		{gormx.TraceCommit, 1},
+	}, steps)
+
+	if len(trace) == 8 {
+		// each operation applies to the savepoint of its transaction
+		assert.Equal(trace[1].SavepointID, trace[2].SavepointID)
+		assert.Equal(trace[3].SavepointID, trace[4].SavepointID)
+		assert.Equal(trace[5].SavepointID, trace[6].SavepointID)
+		assert.Equal(trace[0].SavepointID, trace[7].SavepointID)
+		assert.NotEqual(trace[1].SavepointID, trace[3].SavepointID)
+	}
+}
@@ -0,0 +1,160 @@
+package gormx
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// WithIndependentTransaction runs fn in a new top-level transaction that is
+// independent from any transaction currently open on g: it is committed if fn
+// returns nil and rolled back otherwise, whatever happens to the outer one.
+//
+// The independent transaction runs on its own connection, so while it is open
+// g holds two connections from the pool. With a pool limited to a single
+// connection, calling it within a transaction blocks forever.
+func (g *gormx) WithIndependentTransaction(ctx context.Context, fn func(tx *gormx) error) error {
+	return g.fork().run(ctx, nil, fn)
+}
+
+// WithoutTransaction runs fn on the underlying gorm db, outside of any
+// transaction open on g: its statements are autocommitted, so they persist
+// even if that transaction is rolled back, e.g. for audit or metrics writes.
+//
+// Like WithIndependentTransaction, it uses a second connection from the pool
+// while a transaction is open on g.
+func (g *gormx) WithoutTransaction(fn func(db *gorm.DB) error) error {
+	return fn(g.db.WithContext(g.defaultCtx))
+}
+
+// ReadSnapshot runs fn in a new read-only REPEATABLE READ transaction on its
+// own connection, which can be used even while a transaction is open on g.
+// All the reads of fn see the same snapshot of the committed data: they
+// don't see the uncommitted changes of the transaction open on g, nor the
+// ones committed by others after the first read.
+//
+// Like WithIndependentTransaction, it holds a second connection from the pool
+// while a transaction is open on g.
+func (g *gormx) ReadSnapshot(ctx context.Context, fn func(tx *gormx) error) error {
+	return g.fork().run(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}, fn)
+}
+
+// WithTransactionRetry runs fn in a transaction, committed if fn returns nil
+// and rolled back otherwise. When the transaction fails on a deadlock or a
+// serialization failure, it is rolled back and fn is run again in a fresh
+// transaction, up to maxRetries times.
+//
+// fn may run several times, so it must be idempotent: side effects other than
+// the statements run through tx, such as calls to other services, are not
+// undone by the rollback.
+//
+// Called within a transaction, fn runs once in a nested one without retrying:
+// a deadlock rolls back the whole transaction, which must be retried by its
+// owner.
+func (g *gormx) WithTransactionRetry(ctx context.Context, maxRetries int, fn func(tx *gormx) error) error {
+	if g.inTransaction() {
+		return g.run(ctx, nil, fn)
+	}
+
+	ctx, err := g.context(ctx)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := g.run(ctx, nil, fn)
+		if err == nil || attempt >= maxRetries || !isRetryableTransaction(err) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return err
+		}
+	}
+}
+
+// RetrySavepoint runs fn in a nested transaction of the one open on g,
+// released if fn returns nil and rolled back otherwise. When fn fails on a
+// Postgres serialization failure, e.g. under SERIALIZABLE isolation, only its
+// savepoint is rolled back and fn is run again in a fresh one, up to
+// maxRetries times, leaving the work done before it in the transaction
+// untouched. It returns ErrNotInTransaction outside of a transaction.
+//
+// Like with WithTransactionRetry, fn may run several times, so it must be
+// idempotent.
+func (g *gormx) RetrySavepoint(ctx context.Context, maxRetries int, fn func(tx *gormx) error) error {
+	if !g.inTransaction() {
+		return ErrNotInTransaction
+	}
+
+	ctx, err := g.context(ctx)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := g.run(ctx, nil, fn)
+		if err == nil || attempt >= maxRetries || !isSerializationFailure(err) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return err
+		}
+	}
+}
+
+// run runs fn in a transaction of g, begun with opts if it is a top-level
+// one, committed if fn returns nil and rolled back otherwise, or if it panics.
+func (g *gormx) run(ctx context.Context, opts *sql.TxOptions, fn func(tx *gormx) error) (err error) {
+	tx, err := g.beginTxx(ctx, opts)
+	if err != nil {
+		if tx != nil {
+			tx.Rollbackx()
+		}
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollbackx()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollbackx()
+		return err
+	}
+
+	return tx.Commitx()
+}
+
+// Transaction runs fc in a nested gormx transaction, committed if fc returns
+// nil and rolled back otherwise. It takes over gorm's Transaction on the
+// transaction handle, so that transactions nested through it are tracked by
+// gormx and always backed by a savepoint, whatever gorm's
+// DisableNestedTransaction setting. opts are ignored.
+//
+// Calling Transaction on the gorm DB returned by Tx instead lets gorm manage
+// the nesting with its own savepoints, which it only creates while
+// DisableNestedTransaction is false. That is gorm's default, and the
+// recommended setting with gormx.
+func (g *gormx) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
+	ctx := g.defaultCtx
+	if g.inTransaction() {
+		ctx = g.DB.Statement.Context
+	}
+
+	return g.run(ctx, nil, func(tx *gormx) error {
+		return fc(tx.DB)
+	})
+}
+
+// checkNestedTransaction warns when gorm's nested transactions are disabled,
+// as Transaction calls made on Tx then run without a savepoint and can't be
+// rolled back on their own.
+func (g *gormx) checkNestedTransaction() {
+	if g.db.DisableNestedTransaction {
+		g.db.Logger.Warn(g.defaultCtx, "gormx: gorm's DisableNestedTransaction is set, Transaction calls made on Tx() won't be backed by savepoints")
+	}
+}
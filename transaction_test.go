@@ -0,0 +1,251 @@
+package gormx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pnuggz/gormx"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestGormx_WithIndependentTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	err := gx.WithIndependentTransaction(ctx, func(tx *gormx.Transaction) error {
+		return tx.Exec("INSERT INTO t2(id) VALUES('audit')").Error
+	})
+	assert.NoError(err)
+
+	assert.NoError(tx.Rollbackx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Equal([]T2{{ID: "audit"}}, t2s)
+}
+
+func TestGormx_WithoutTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	tx := gx.BeginTxx(context.Background())
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	err := tx.WithoutTransaction(func(db *gorm.DB) error {
+		return db.Exec("INSERT INTO t2(id) VALUES('metrics')").Error
+	})
+	assert.NoError(err)
+
+	assert.NoError(tx.Rollbackx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 0)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Equal([]T2{{ID: "metrics"}}, t2s)
+}
+
+func TestGormx_Transaction(t *testing.T) {
+	assert := assert.New(t)
+
+	type testCase struct {
+		name     string
+		disabled bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "gorm nested transactions enabled",
+			disabled: false,
+		},
+		{
+			name:     "gorm nested transactions disabled",
+			disabled: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := createConnection(t).Session(&gorm.Session{DisableNestedTransaction: tc.disabled})
+			gx, _ := gormx.New(db)
+			defer gx.Close()
+
+			ctx := context.Background()
+
+			tx := gx.BeginTxx(ctx)
+			tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+			err := tx.Transaction(func(db *gorm.DB) error {
+				db.Exec("INSERT INTO t2(id) VALUES('abc')")
+				return errors.New("failure")
+			})
+			assert.Error(err)
+
+			err = tx.Transaction(func(db *gorm.DB) error {
+				return db.Exec("INSERT INTO t3(id) VALUES('abc')").Error
+			})
+			assert.NoError(err)
+
+			assert.NoError(tx.Commitx())
+
+			var t1s []T1
+			gx.Gorm().Find(&t1s)
+			assert.Len(t1s, 1)
+
+			var t2s []T2
+			gx.Gorm().Find(&t2s)
+			assert.Len(t2s, 0)
+
+			var t3s []T3
+			gx.Gorm().Find(&t3s)
+			assert.Len(t3s, 1)
+		})
+	}
+}
+
+func TestGormx_Tx_GormTransaction(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	nested := gx.BeginTxx(ctx)
+	err := nested.Tx().Transaction(func(db *gorm.DB) error {
+		db.Exec("INSERT INTO t2(id) VALUES('abc')")
+		return errors.New("failure")
+	})
+	assert.Error(err)
+	assert.NoError(gx.Err())
+	assert.NoError(nested.Commitx())
+
+	assert.NoError(tx.Commitx())
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	assert.Len(t1s, 1)
+
+	var t2s []T2
+	gx.Gorm().Find(&t2s)
+	assert.Len(t2s, 0)
+}
+
+func TestGormx_WithTransactionRetry(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	attempts := 0
+	err := gx.WithTransactionRetry(ctx, 3, func(tx *gormx.Transaction) error {
+		attempts++
+		tx.Exec("INSERT INTO t1(id) VALUES(?)", fmt.Sprintf("attempt_%d", attempts))
+		if attempts == 1 {
+			return &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(2, attempts)
+
+	var t1s []T1
+	gx.Gorm().Find(&t1s)
+	if assert.Len(t1s, 1) {
+		assert.Equal("attempt_2", t1s[0].ID)
+	}
+}
+
+func TestGormx_WithTransactionRetry_Exhausted(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+	attempts := 0
+	err := gx.WithTransactionRetry(ctx, 2, func(tx *gormx.Transaction) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(err, deadlock)
+	assert.Equal(3, attempts)
+
+	// a nil context falls back to the default one
+	attempts = 0
+	err = gx.WithTransactionRetry(nil, 2, func(tx *gormx.Transaction) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(err, deadlock)
+	assert.Equal(3, attempts)
+
+	// other errors aren't retried
+	attempts = 0
+	failure := errors.New("failure")
+	err = gx.WithTransactionRetry(ctx, 2, func(tx *gormx.Transaction) error {
+		attempts++
+		return failure
+	})
+	assert.ErrorIs(err, failure)
+	assert.Equal(1, attempts)
+}
+
+func TestGormx_ReadSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	db := createConnection(t)
+	gx, _ := gormx.New(db)
+	defer gx.Close()
+
+	ctx := context.Background()
+
+	tx := gx.BeginTxx(ctx)
+	tx.Exec("INSERT INTO t1(id) VALUES('abc')")
+
+	err := gx.ReadSnapshot(ctx, func(snapshot *gormx.Transaction) error {
+		var t1s []T1
+		assert.NoError(snapshot.Find(&t1s).Error)
+		assert.Len(t1s, 0)
+
+		// the snapshot is read-only
+		assert.Error(snapshot.Exec("INSERT INTO t2(id) VALUES('abc')").Error)
+		return nil
+	})
+	assert.NoError(err)
+
+	assert.NoError(tx.Commitx())
+
+	err = gx.ReadSnapshot(ctx, func(snapshot *gormx.Transaction) error {
+		var t1s []T1
+		assert.NoError(snapshot.Find(&t1s).Error)
+		assert.Len(t1s, 1)
+		return nil
+	})
+	assert.NoError(err)
+}